@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+)
+
+func changesHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: "since must be a generation"})
+			return
+		}
+
+		feed, resync := dirSync.Changes(since)
+		if resync != nil {
+			writeJson(w, http.StatusGone, resync)
+			return
+		}
+		writeJson(w, http.StatusOK, feed)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+)
+
+// maxBatchChecks limits the number of checks of a single batch request.
+const maxBatchChecks = 1000
+
+type checkResponse struct {
+	Generation uint64 `json:"generation"`
+	*sync.CheckResult
+}
+
+type batchCheckRequest struct {
+	sync.CheckOptions
+	Checks []struct {
+		Member string `json:"member"`
+		Group  string `json:"group"`
+	} `json:"checks"`
+}
+
+type batchCheckResponse struct {
+	Generation uint64              `json:"generation"`
+	Results    []*sync.CheckResult `json:"results"`
+}
+
+func checkHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		member := query.Get("member")
+		group := query.Get("group")
+		if member == "" || group == "" {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: "member and group are required"})
+			return
+		}
+
+		options := sync.CheckOptions{}
+		options.Transitive, _ = strconv.ParseBool(query.Get("transitive"))
+		options.ExcludeSuspended, _ = strconv.ParseBool(query.Get("exclude_suspended"))
+		if roles := query.Get("roles"); roles != "" {
+			options.Roles = strings.Split(roles, ",")
+		}
+
+		snapshot := dirSync.Snapshot()
+		result := snapshot.Check(member, group, options)
+		if result.Error != "" {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: result.Error})
+			return
+		}
+		writeJson(w, http.StatusOK, checkResponse{Generation: snapshot.Generation, CheckResult: result})
+	}
+}
+
+func batchCheckHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request batchCheckRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: "invalid check request: " + err.Error()})
+			return
+		}
+		if len(request.Checks) > maxBatchChecks {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: "at most " + strconv.Itoa(maxBatchChecks) + " checks are allowed per request"})
+			return
+		}
+
+		snapshot := dirSync.Snapshot()
+		response := batchCheckResponse{
+			Generation: snapshot.Generation,
+			Results:    make([]*sync.CheckResult, 0, len(request.Checks)),
+		}
+		for _, check := range request.Checks {
+			response.Results = append(response.Results, snapshot.Check(check.Member, check.Group, request.CheckOptions))
+		}
+		writeJson(w, http.StatusOK, response)
+	}
+}
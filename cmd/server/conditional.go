@@ -0,0 +1,126 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+)
+
+const (
+	gzipEncoding    = "gzip"
+	deflateEncoding = "deflate"
+)
+
+// writeSnapshotJson responds with a body derived from the snapshot with the
+// given content hash. The hash serves as strong ETag, so clients that already
+// have the current representation receive 304 Not Modified. The body is
+// compressed with gzip or deflate if the client accepts it.
+func writeSnapshotJson(w http.ResponseWriter, r *http.Request, hash string, body *sync.EncodedJson) {
+	w.Header().Set("Vary", "Accept-Encoding")
+
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if hash != "" {
+		w.Header().Set("ETag", etag(hash, encoding))
+		if matchesETag(r.Header.Get("If-None-Match"), hash) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch encoding {
+	case gzipEncoding:
+		w.Header().Set("Content-Encoding", gzipEncoding)
+		if body.Gzip != nil {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body.Gzip)))
+			w.Write(body.Gzip)
+			return
+		}
+		writer := gzip.NewWriter(w)
+		writer.Write(body.Json)
+		writer.Close()
+	case deflateEncoding:
+		w.Header().Set("Content-Encoding", deflateEncoding)
+		// The deflate content coding is the zlib format, not raw deflate
+		writer := zlib.NewWriter(w)
+		writer.Write(body.Json)
+		writer.Close()
+	default:
+		w.Header().Set("Content-Length", strconv.Itoa(len(body.Json)))
+		w.Write(body.Json)
+	}
+}
+
+// writeSnapshotValue encodes the value and responds with it like
+// writeSnapshotJson.
+func writeSnapshotValue(w http.ResponseWriter, r *http.Request, hash string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, fmt.Sprintf("Failed to marshal json: %v\n", err))
+		return
+	}
+	writeSnapshotJson(w, r, hash, &sync.EncodedJson{Json: data})
+}
+
+// etag returns the strong ETag of a representation. Every content coding is a
+// different representation and therefore needs its own tag.
+func etag(hash string, encoding string) string {
+	if encoding == "" {
+		return `"` + hash + `"`
+	}
+	return `"` + hash + "-" + encoding + `"`
+}
+
+// matchesETag reports whether the If-None-Match header contains a tag of any
+// representation of the content with the given hash, using the weak
+// comparison required for If-None-Match.
+func matchesETag(ifNoneMatch string, hash string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" {
+			return true
+		}
+		for _, encoding := range []string{"", gzipEncoding, deflateEncoding} {
+			if tag == etag(hash, encoding) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks gzip or deflate from the Accept-Encoding header,
+// preferring gzip. An empty result means no compression.
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				quality, _ = strconv.ParseFloat(param[2:], 64)
+			}
+		}
+		accepted[coding] = quality > 0
+	}
+
+	for _, encoding := range []string{gzipEncoding, deflateEncoding} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
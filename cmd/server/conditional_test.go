@@ -0,0 +1,56 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSnapshotJson(t *testing.T) {
+	a := assert.New(t)
+
+	body, err := sync.EncodeJson(map[string]string{"key": "value"})
+	a.NoError(err)
+
+	request := httptest.NewRequest("GET", "/api/directory", nil)
+	response := httptest.NewRecorder()
+	writeSnapshotJson(response, request, "hash", body)
+	a.Equal(http.StatusOK, response.Code)
+	a.Equal(`"hash"`, response.Header().Get("ETag"))
+	a.Equal(`{"key":"value"}`, response.Body.String())
+
+	request.Header.Set("Accept-Encoding", "deflate, gzip;q=0.5")
+	response = httptest.NewRecorder()
+	writeSnapshotJson(response, request, "hash", body)
+	a.Equal("gzip", response.Header().Get("Content-Encoding"))
+	a.Equal(`"hash-gzip"`, response.Header().Get("ETag"))
+	reader, err := gzip.NewReader(response.Body)
+	a.NoError(err)
+	decompressed, err := ioutil.ReadAll(reader)
+	a.NoError(err)
+	a.Equal(`{"key":"value"}`, string(decompressed))
+
+	request.Header.Set("Accept-Encoding", "gzip;q=0, deflate")
+	request.Header.Set("If-None-Match", `"other", W/"hash-gzip"`)
+	response = httptest.NewRecorder()
+	writeSnapshotJson(response, request, "hash", body)
+	a.Equal(http.StatusNotModified, response.Code)
+	a.Equal(`"hash-deflate"`, response.Header().Get("ETag"))
+	a.Empty(response.Body.String())
+
+	request.Header.Del("If-None-Match")
+	response = httptest.NewRecorder()
+	writeSnapshotJson(response, request, "hash", body)
+	a.Equal("deflate", response.Header().Get("Content-Encoding"))
+	zlibReader, err := zlib.NewReader(response.Body)
+	a.NoError(err)
+	decompressed, err = ioutil.ReadAll(zlibReader)
+	a.NoError(err)
+	a.Equal(`{"key":"value"}`, string(decompressed))
+}
@@ -0,0 +1,14 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+func domainsHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, directory.ToDomainList(dirSync.Snapshot().Domains))
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+)
+
+func explainHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		memberKey := r.URL.Query().Get("member")
+		groupKey := r.URL.Query().Get("group")
+		if memberKey == "" || groupKey == "" {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: "member and group are required"})
+			return
+		}
+
+		snapshot := dirSync.Snapshot()
+		member, ok := snapshot.Member(memberKey)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "member " + memberKey + " not found"})
+			return
+		}
+		group, ok := snapshot.Group(groupKey)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "group " + groupKey + " not found"})
+			return
+		}
+
+		writeJson(w, http.StatusOK, snapshot.Explain(member, group.Id))
+	}
+}
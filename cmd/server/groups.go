@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/gorilla/mux"
+)
+
+func groupMembersHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		snapshot := dirSync.Snapshot()
+		group, ok := snapshot.Group(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "group " + idOrEmail + " not found"})
+			return
+		}
+		transitive := r.URL.Query().Get("transitive") == "true"
+		writeJson(w, http.StatusOK, snapshot.GroupMembers(group.Id, transitive))
+	}
+}
+
+func groupHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		group, ok := dirSync.Snapshot().Group(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "group " + idOrEmail + " not found"})
+			return
+		}
+		writeJson(w, http.StatusOK, group)
+	}
+}
+
+func groupOwnersHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		snapshot := dirSync.Snapshot()
+		group, ok := snapshot.Group(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "group " + idOrEmail + " not found"})
+			return
+		}
+		writeJson(w, http.StatusOK, snapshot.GroupOwners(group.Id))
+	}
+}
+
+type groupPageResponse struct {
+	Generation uint64 `json:"generation"`
+	*directory.GroupPage
+}
+
+func searchGroupsHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := directory.ParseGroupQuery(r.URL.Query())
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		snapshot := dirSync.Snapshot()
+		writeSnapshotValue(w, r, snapshot.Hash, groupPageResponse{
+			Generation: snapshot.Generation,
+			GroupPage:  query.Search(snapshot.Groups),
+		})
+	}
+}
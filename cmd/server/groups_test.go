@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupQueriesOnlyOnSearch(t *testing.T) {
+	a := assert.New(t)
+
+	location, err := ioutil.TempDir("", "directory")
+	a.NoError(err)
+	defer os.RemoveAll(location)
+	a.NoError(ioutil.WriteFile(filepath.Join(location, "directory.json"),
+		[]byte(`{"g1":{"id":"g1","email":"team@your.org"},"g2":{"id":"g2","email":"other@your.org"}}`), 0644))
+	dirSync, err := sync.Mock(location)
+	a.NoError(err)
+
+	response := httptest.NewRecorder()
+	directoryHandler(dirSync)(response, httptest.NewRequest("GET", "/api/directory?q=team&limit=1", nil))
+	a.Equal(http.StatusOK, response.Code)
+	var groups map[string]*directory.Group
+	a.NoError(json.Unmarshal(response.Body.Bytes(), &groups))
+	a.Len(groups, 2)
+
+	response = httptest.NewRecorder()
+	searchGroupsHandler(dirSync)(response, httptest.NewRequest("GET", "/api/groups/search?q=team&limit=1", nil))
+	a.Equal(http.StatusOK, response.Code)
+	var page struct {
+		Groups []*directory.Group `json:"groups"`
+		Total  int                `json:"total"`
+	}
+	a.NoError(json.Unmarshal(response.Body.Bytes(), &page))
+	a.Equal(1, page.Total)
+	a.Equal("g1", page.Groups[0].Id)
+
+	response = httptest.NewRecorder()
+	searchGroupsHandler(dirSync)(response, httptest.NewRequest("GET", "/api/groups/search?limit=0", nil))
+	a.Equal(http.StatusBadRequest, response.Code)
+}
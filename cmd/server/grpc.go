@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/fabzo/gcloud-directory-service/rpc"
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/webhooks"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newServers creates the server for the API. With --grpc the gRPC API is
+// served on its own port as well. Streaming calls end once the servers shut
+// down.
+func newServers(dirSync sync.DirSync, dispatcher *webhooks.Dispatcher) []server {
+	shutdown := make(chan struct{})
+	apiServer := &http.Server{
+		Addr:    ":" + strconv.Itoa(port),
+		Handler: newRouter(dirSync, dispatcher, shutdown),
+	}
+	apiServer.RegisterOnShutdown(func() { close(shutdown) })
+	servers := []server{apiServer}
+
+	if grpcEnabled {
+		servers = append(servers, newGrpcServer(dirSync, ":"+strconv.Itoa(grpcPort)))
+	}
+	return servers
+}
+
+// grpcServer serves the gRPC API and shuts down like an HTTP server.
+type grpcServer struct {
+	server   *grpc.Server
+	addr     string
+	shutdown chan struct{}
+}
+
+func newGrpcServer(dirSync sync.DirSync, addr string) *grpcServer {
+	shutdown := make(chan struct{})
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := authenticate(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authenticate(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+	rpc.RegisterDirectoryServiceServer(server, rpc.NewServer(dirSync, shutdown))
+
+	return &grpcServer{
+		server:   server,
+		addr:     addr,
+		shutdown: shutdown,
+	}
+}
+
+func (s *grpcServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
+}
+
+// Shutdown ends the streaming calls and waits for all calls to complete. The
+// remaining connections are closed once ctx is done.
+func (s *grpcServer) Shutdown(ctx context.Context) error {
+	close(s.shutdown)
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// authenticate checks the basic auth login sent in the authorization metadata
+// of the call.
+func authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r := &http.Request{Header: http.Header{"Authorization": md["authorization"]}}
+	user, pass, _ := r.BasicAuth()
+	if !check(user, pass) {
+		return status.Errorf(codes.Unauthenticated, "unauthorized")
+	}
+	return nil
+}
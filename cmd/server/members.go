@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/gorilla/mux"
+)
+
+type memberResponse struct {
+	*directory.Member
+	Groups []*sync.MemberGroup `json:"groups"`
+}
+
+func memberHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		snapshot := dirSync.Snapshot()
+		member, ok := snapshot.Member(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "member " + idOrEmail + " not found"})
+			return
+		}
+		transitive := r.URL.Query().Get("transitive") == "true"
+		writeJson(w, http.StatusOK, memberResponse{
+			Member: member,
+			Groups: snapshot.MemberGroups(member.Id, transitive),
+		})
+	}
+}
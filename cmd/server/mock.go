@@ -1,14 +1,13 @@
 package server
 
 import (
-	"net/http"
+	"context"
 	"os"
 	"strings"
 
-	"strconv"
+	"time"
 
 	"github.com/fabzo/gcloud-directory-service/sync"
-	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +16,9 @@ func init() {
 	Mock.PersistentFlags().StringVarP(&basicAuth, "basic-auth", "b", "", "Basic auth login in the form of <username>:<password>.")
 	Mock.PersistentFlags().StringVarP(&storageLocation, "storage-location", "l", "", "Storage location where the directory.json is located")
 	Mock.PersistentFlags().IntVarP(&port, "port", "p", 8080, "Port for the API")
+	Mock.PersistentFlags().BoolVar(&grpcEnabled, "grpc", false, "Serve the gRPC API on the gRPC port")
+	Mock.PersistentFlags().IntVar(&grpcPort, "grpc-port", 9090, "Port for the gRPC API")
+	Mock.PersistentFlags().IntVar(&shutdownTimeout, "shutdown-timeout", 30, "Time in seconds to drain connections on shutdown")
 }
 
 var Mock = &cobra.Command{
@@ -48,17 +50,9 @@ var Mock = &cobra.Command{
 		logrus.Infof("basic auth           : %v", basicAuth)
 		logrus.Infof("storage location     : %v", storageLocation)
 
-		mockSync.RunSyncLoop()
+		ctx, stopSync := context.WithCancel(context.Background())
+		mockSync.RunSyncLoop(ctx)
 
-		r := mux.NewRouter()
-		r.HandleFunc("/", auth(rootHandler()))
-		r.HandleFunc("/api", auth(rootHandler()))
-		r.HandleFunc("/api/status", auth(statusHandler(mockSync)))
-		r.HandleFunc("/api/directory", auth(directoryHandler(mockSync)))
-		r.HandleFunc("/api/groups", auth(groupsHandler(mockSync)))
-		r.HandleFunc("/api/members", auth(membersHandler(mockSync)))
-		r.HandleFunc("/health", healthHandler())
-
-		http.ListenAndServe(":"+strconv.Itoa(port), r)
+		os.Exit(serve(newServers(mockSync, nil), mockSync, stopSync, time.Duration(shutdownTimeout)*time.Second))
 	},
 }
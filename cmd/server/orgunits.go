@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/gorilla/mux"
+)
+
+func orgUnitsHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, dirSync.Snapshot().OrgUnitTree)
+	}
+}
+
+func orgUnitUsersHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			path = directory.RootOrgUnitPath
+		}
+		recursive := r.URL.Query().Get("recursive") == "true"
+
+		snapshot := dirSync.Snapshot()
+		if _, ok := snapshot.OrgUnit(path); !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "org unit " + path + " not found"})
+			return
+		}
+		writeJson(w, http.StatusOK, snapshot.UsersInOrgUnit(path, recursive))
+	}
+}
+
+func userOrgUnitHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		snapshot := dirSync.Snapshot()
+		user, ok := snapshot.User(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "user " + idOrEmail + " not found"})
+			return
+		}
+		orgUnit, ok := snapshot.OrgUnit(user.OrgUnitPath)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "org unit " + user.OrgUnitPath + " not found"})
+			return
+		}
+		writeJson(w, http.StatusOK, orgUnit)
+	}
+}
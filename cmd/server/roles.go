@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/gorilla/mux"
+)
+
+func adminRolesHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminRoles := dirSync.Snapshot().AdminRoles
+		if adminRoles == nil {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+			return
+		}
+		writeJson(w, http.StatusOK, adminRoles)
+	}
+}
+
+func userAdminRolesHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		snapshot := dirSync.Snapshot()
+
+		// Without synced users the role assignments can only be looked up by
+		// the user id.
+		userId := idOrEmail
+		if snapshot.Users != nil {
+			user, ok := snapshot.User(idOrEmail)
+			if !ok {
+				writeJson(w, http.StatusNotFound, errorResponse{Error: "user " + idOrEmail + " not found"})
+				return
+			}
+			userId = user.Id
+		}
+
+		writeJson(w, http.StatusOK, snapshot.UserAdminRoles(userId))
+	}
+}
@@ -1,16 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 
-	"strconv"
+	"time"
 
 	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
 	"github.com/fabzo/gcloud-directory-service/utils"
+	"github.com/fabzo/gcloud-directory-service/webhooks"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -21,8 +24,32 @@ var subject string
 var customerId string
 var domain string
 var syncInterval int
+var syncWorkers int
+var incremental bool
+var fullSyncInterval int
+var changeSync bool
+var changeSyncInterval int
+var qps float64
+var maxRetries int
+var maxGroupDrop float64
+var maxMemberDrop float64
+var maxMembershipDrop float64
+var historySize int
+var changeLogSize int
+var shutdownTimeout int
+var syncUsers bool
+var syncOrgUnits bool
+var syncGroupSettings bool
+var syncAdminRoles bool
+var syncDomains bool
+var maxNestingDepth int
+var webhooksFile string
+var webhookMaxAttempts int
+var webhookBackoff int
 var storageLocation string
 var port int
+var grpcEnabled bool
+var grpcPort int
 
 var basicAuth string
 
@@ -32,9 +59,33 @@ func init() {
 	Command.PersistentFlags().StringVarP(&customerId, "customer-id", "c", "my_customer", "The gsuite customer id")
 	Command.PersistentFlags().StringVarP(&domain, "domain", "d", "", "The gsuite domain for which to retrieve the groups (default '')")
 	Command.PersistentFlags().IntVarP(&syncInterval, "sync-interval", "i", 30, "Sync interval in minutes")
+	Command.PersistentFlags().BoolVar(&incremental, "incremental", false, "Only refetch the members of groups whose ETag changed since the last sync")
+	Command.PersistentFlags().IntVar(&fullSyncInterval, "full-sync-interval", 360, "Interval in minutes for a full sync when running incrementally")
+	Command.PersistentFlags().BoolVar(&changeSync, "change-sync", false, "Refresh changed groups in between syncs based on the admin reports activity feed")
+	Command.PersistentFlags().IntVar(&changeSyncInterval, "change-sync-interval", 60, "Interval in seconds for reading the activity feed when change sync is enabled")
+	Command.PersistentFlags().Float64Var(&qps, "qps", 20, "Maximum number of google API calls per second (0 disables the limit)")
+	Command.PersistentFlags().IntVar(&maxRetries, "max-retries", 5, "Maximum number of retries for rate limited or failed google API calls")
+	Command.PersistentFlags().Float64Var(&maxGroupDrop, "max-group-drop", 20, "Maximum drop of groups in percent before a synced directory is quarantined (0 disables the check)")
+	Command.PersistentFlags().Float64Var(&maxMemberDrop, "max-member-drop", 20, "Maximum drop of members in percent before a synced directory is quarantined (0 disables the check)")
+	Command.PersistentFlags().Float64Var(&maxMembershipDrop, "max-membership-drop", 20, "Maximum drop of memberships in percent before a synced directory is quarantined (0 disables the check)")
+	Command.PersistentFlags().IntVar(&maxNestingDepth, "max-nesting-depth", directory.DefaultMaxNestingDepth, "Maximum number of nested groups followed when resolving transitive memberships (0 disables the limit)")
+	Command.PersistentFlags().IntVar(&historySize, "history-size", 50, "Number of sync runs kept in the sync history")
+	Command.PersistentFlags().IntVar(&changeLogSize, "change-log-size", 100, "Number of directory generations whose changes are kept for /api/changes and /api/stream")
+	Command.PersistentFlags().BoolVar(&syncUsers, "sync-users", false, "Retrieve the users of the customer alongside the groups")
+	Command.PersistentFlags().BoolVar(&syncOrgUnits, "sync-orgunits", false, "Retrieve the org unit hierarchy of the customer")
+	Command.PersistentFlags().BoolVar(&syncGroupSettings, "sync-group-settings", false, "Retrieve the settings of every group alongside its members")
+	Command.PersistentFlags().BoolVar(&syncAdminRoles, "sync-admin-roles", false, "Retrieve the admin roles and their assignments")
+	Command.PersistentFlags().BoolVar(&syncDomains, "sync-domains", false, "Retrieve the domains and domain aliases to resolve email addresses in alias domains")
+	Command.PersistentFlags().StringVar(&webhooksFile, "webhooks", "", "Location of a json file with webhook subscriptions for directory changes (optional)")
+	Command.PersistentFlags().IntVar(&webhookMaxAttempts, "webhook-max-attempts", 5, "Maximum number of attempts to deliver a webhook payload before it becomes a dead letter")
+	Command.PersistentFlags().IntVar(&webhookBackoff, "webhook-backoff", 5, "Time in seconds before the first retry of a failed webhook delivery, doubled for every further retry")
+	Command.PersistentFlags().IntVarP(&syncWorkers, "sync-workers", "w", 8, "Number of groups whose members are retrieved in parallel")
 	Command.PersistentFlags().StringVarP(&basicAuth, "basic-auth", "b", "", "Basic auth login in the form of <username>:<password>. Random login is generated if not set")
 	Command.PersistentFlags().StringVarP(&storageLocation, "storage-location", "l", "", "Storage location for faster restores (optional)")
 	Command.PersistentFlags().IntVarP(&port, "port", "p", 8080, "Port for the API")
+	Command.PersistentFlags().BoolVar(&grpcEnabled, "grpc", false, "Serve the gRPC API on the gRPC port")
+	Command.PersistentFlags().IntVar(&grpcPort, "grpc-port", 9090, "Port for the gRPC API")
+	Command.PersistentFlags().IntVar(&shutdownTimeout, "shutdown-timeout", 30, "Time in seconds to drain connections and stop the sync on shutdown")
 }
 
 var Command = &cobra.Command{
@@ -48,30 +99,102 @@ var Command = &cobra.Command{
 		}
 		if basicAuth == "" {
 			basicAuth = "admin:" + utils.RandString(25)
-			logrus.Warnf("No basic auth login provided. Randomly generated basic auth is " + basicAuth)
+			logrus.Warnf("No basic auth login provided. Randomly generated basic auth is %s", basicAuth)
 		}
 
-		dirSync, err := sync.New(serviceAccount, subject, customerId, domain, syncInterval, storageLocation)
+		dirSync, err := sync.New(sync.Config{
+			ServiceAccountFile: serviceAccount,
+			Subject:            subject,
+			CustomerId:         customerId,
+			Domain:             domain,
+			SyncInterval:       syncInterval,
+			SyncWorkers:        syncWorkers,
+			StorageLocation:    storageLocation,
+			Incremental:        incremental,
+			FullSyncInterval:   fullSyncInterval,
+			ChangeSync:         changeSync,
+			ChangeSyncInterval: changeSyncInterval,
+			QPS:                qps,
+			MaxRetries:         maxRetries,
+			Thresholds: sync.Thresholds{
+				MaxGroupDrop:      maxGroupDrop,
+				MaxMemberDrop:     maxMemberDrop,
+				MaxMembershipDrop: maxMembershipDrop,
+			},
+			HistorySize:       historySize,
+			ChangeLogSize:     changeLogSize,
+			SyncUsers:         syncUsers,
+			SyncOrgUnits:      syncOrgUnits,
+			SyncGroupSettings: syncGroupSettings,
+			SyncAdminRoles:    syncAdminRoles,
+			SyncDomains:       syncDomains,
+			MaxNestingDepth:   maxNestingDepth,
+		})
 		if err != nil {
 			logrus.Errorf("Could not initiate google sync client: %v", err)
 			os.Exit(1)
 		}
 
-		dirSync.RunSyncLoop()
+		var dispatcher *webhooks.Dispatcher
+		if webhooksFile != "" {
+			configs, err := webhooks.LoadConfig(webhooksFile)
+			if err != nil {
+				logrus.Errorf("Could not load webhooks: %v", err)
+				os.Exit(1)
+			}
+			dispatcher = webhooks.New(dirSync, configs, webhookMaxAttempts, time.Duration(webhookBackoff)*time.Second)
+		}
 
-		r := mux.NewRouter()
-		r.HandleFunc("/", auth(rootHandler()))
-		r.HandleFunc("/api", auth(rootHandler()))
-		r.HandleFunc("/api/status", auth(statusHandler(dirSync)))
-		r.HandleFunc("/api/directory", auth(directoryHandler(dirSync)))
-		r.HandleFunc("/api/groups", auth(groupsHandler(dirSync)))
-		r.HandleFunc("/api/members", auth(membersHandler(dirSync)))
-		r.HandleFunc("/health", healthHandler())
-		http.ListenAndServe(":"+strconv.Itoa(port), r)
+		ctx, stopSync := context.WithCancel(context.Background())
+		if dispatcher != nil {
+			dispatcher.Run(ctx)
+		}
+		dirSync.RunSyncLoop(ctx)
 
+		os.Exit(serve(newServers(dirSync, dispatcher), dirSync, stopSync, time.Duration(shutdownTimeout)*time.Second))
 	},
 }
 
+// newRouter registers the API handlers. The dispatcher is nil if no webhooks
+// are configured. Streaming handlers end when shutdown is closed.
+func newRouter(dirSync sync.DirSync, dispatcher *webhooks.Dispatcher, shutdown <-chan struct{}) *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/", auth(rootHandler()))
+	r.HandleFunc("/api", auth(rootHandler()))
+	r.HandleFunc("/api/status", auth(statusHandler(dirSync)))
+	r.HandleFunc("/api/status/history", auth(historyHandler(dirSync)))
+	r.HandleFunc("/api/sync", auth(triggerSyncHandler(dirSync))).Methods("POST")
+	r.HandleFunc("/api/sync", auth(cancelSyncHandler(dirSync))).Methods("DELETE")
+	r.HandleFunc("/api/quarantine", auth(quarantineHandler(dirSync))).Methods("GET")
+	r.HandleFunc("/api/quarantine", auth(applyQuarantineHandler(dirSync))).Methods("POST")
+	r.HandleFunc("/api/quarantine", auth(discardQuarantineHandler(dirSync))).Methods("DELETE")
+	r.HandleFunc("/api/quarantine/directory", auth(quarantineDirectoryHandler(dirSync))).Methods("GET")
+	r.HandleFunc("/api/directory", auth(directoryHandler(dirSync)))
+	r.HandleFunc("/api/changes", auth(changesHandler(dirSync)))
+	r.HandleFunc("/api/stream", auth(streamHandler(dirSync, shutdown)))
+	r.HandleFunc("/api/webhooks", auth(webhooksHandler(dispatcher)))
+	r.HandleFunc("/api/groups", auth(groupsHandler(dirSync)))
+	r.HandleFunc("/api/groups/search", auth(searchGroupsHandler(dirSync)))
+	r.HandleFunc("/api/groups/{idOrEmail}", auth(groupHandler(dirSync)))
+	r.HandleFunc("/api/groups/{idOrEmail}/members", auth(groupMembersHandler(dirSync)))
+	r.HandleFunc("/api/groups/{idOrEmail}/owners", auth(groupOwnersHandler(dirSync)))
+	r.HandleFunc("/api/members", auth(membersHandler(dirSync)))
+	r.HandleFunc("/api/members/{idOrEmail}", auth(memberHandler(dirSync)))
+	r.HandleFunc("/api/explain", auth(explainHandler(dirSync)))
+	r.HandleFunc("/api/check", auth(checkHandler(dirSync))).Methods("GET")
+	r.HandleFunc("/api/check", auth(batchCheckHandler(dirSync))).Methods("POST")
+	r.HandleFunc("/api/users", auth(usersHandler(dirSync)))
+	r.HandleFunc("/api/users/{idOrEmail}", auth(userHandler(dirSync)))
+	r.HandleFunc("/api/users/{idOrEmail}/orgunit", auth(userOrgUnitHandler(dirSync)))
+	r.HandleFunc("/api/users/{idOrEmail}/admin-roles", auth(userAdminRolesHandler(dirSync)))
+	r.HandleFunc("/api/orgunits", auth(orgUnitsHandler(dirSync)))
+	r.HandleFunc("/api/orgunits/users", auth(orgUnitUsersHandler(dirSync)))
+	r.HandleFunc("/api/admin-roles", auth(adminRolesHandler(dirSync)))
+	r.HandleFunc("/api/domains", auth(domainsHandler(dirSync)))
+	r.HandleFunc("/health", healthHandler())
+	return r
+}
+
 func auth(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
@@ -95,9 +218,16 @@ func rootHandler() func(w http.ResponseWriter, r *http.Request) {
 <a href="/">/</a></br>
 <a href="/api">/api</a></br>
 <a href="/api/status">/api/status</a></br>
+<a href="/api/status/history">/api/status/history</a></br>
+<a href="/api/quarantine">/api/quarantine</a></br>
 <a href="/api/directory">/api/directory</a></br>
 <a href="/api/groups">/api/groups</a></br>
 <a href="/api/members">/api/members</a></br>
+<a href="/api/users">/api/users</a></br>
+<a href="/api/orgunits">/api/orgunits</a></br>
+<a href="/api/admin-roles">/api/admin-roles</a></br>
+<a href="/api/domains">/api/domains</a></br>
+<a href="/api/webhooks">/api/webhooks</a></br>
 <a href="/health">/health</a></br>
 		`))
 	}
@@ -121,53 +251,147 @@ func statusHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func historyHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, dirSync.History())
+	}
+}
+
+type triggerSyncResponse struct {
+	Queued bool `json:"queued"`
+}
+
+type cancelSyncResponse struct {
+	Cancelled bool `json:"cancelled"`
+}
+
+func triggerSyncHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusAccepted, triggerSyncResponse{Queued: dirSync.TriggerSync()})
+	}
+}
+
+func cancelSyncHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, cancelSyncResponse{Cancelled: dirSync.CancelSync()})
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type quarantineResponse struct {
+	Applied   bool `json:"applied,omitempty"`
+	Discarded bool `json:"discarded,omitempty"`
+}
+
+func quarantineHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		quarantine := dirSync.Quarantine()
+		if quarantine == nil {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "no directory is quarantined"})
+			return
+		}
+		writeJson(w, http.StatusOK, quarantine)
+	}
+}
+
+func quarantineDirectoryHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		quarantine := dirSync.Quarantine()
+		if quarantine == nil {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "no directory is quarantined"})
+			return
+		}
+		writeJson(w, http.StatusOK, quarantine.Data.Groups)
+	}
+}
+
+func applyQuarantineHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := dirSync.ApplyQuarantine()
+		if err == sync.ErrNoQuarantine {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			writeJson(w, http.StatusConflict, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJson(w, http.StatusOK, quarantineResponse{Applied: true})
+	}
+}
+
+func discardQuarantineHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !dirSync.DiscardQuarantine() {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "no directory is quarantined"})
+			return
+		}
+		writeJson(w, http.StatusOK, quarantineResponse{Discarded: true})
+	}
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Failed to marshal json: %v\n", err)))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(data)
+}
+
 func directoryHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		groups := dirSync.Directory()
-		if groups == nil {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("{}"))
-		} else {
-			err := json.NewEncoder(w).Encode(groups)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(fmt.Sprintf("Failed to marshal directory json: %v\n", err)))
-				return
-			}
+		snapshot := dirSync.Snapshot()
+		if snapshot.Groups == nil || r.URL.Query().Get("names") != "true" {
+			writeSnapshotJson(w, r, snapshot.Hash, snapshot.Encoded.Directory)
+			return
 		}
+		writeSnapshotValue(w, r, snapshot.Hash, directory.WithMemberNames(snapshot.Groups, snapshot.Users))
 	}
 }
 
 func groupsHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		groups := dirSync.EmailToMemberMapping()
-		if groups == nil {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("{}"))
-		} else {
-			err := json.NewEncoder(w).Encode(groups)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(fmt.Sprintf("Failed to marshal groups json: %v\n", err)))
-				return
-			}
+		snapshot := dirSync.Snapshot()
+		filter := directory.NewSettingsFilter(r.URL.Query())
+		if snapshot.EmailToMember != nil && len(filter) > 0 {
+			writeSnapshotValue(w, r, snapshot.Hash, groupsMatching(snapshot.Groups, filter))
+			return
 		}
+		writeSnapshotJson(w, r, snapshot.Hash, snapshot.Encoded.Groups)
 	}
 }
 
+// groupsMatching returns the email mapping of all groups whose settings match
+// the filter. Members are not part of it.
+func groupsMatching(groups map[string]*directory.Group, filter directory.SettingsFilter) map[string]directory.MemberType {
+	emails := map[string]directory.MemberType{}
+	for id, group := range groups {
+		if !filter.Matches(group) {
+			continue
+		}
+		memberType := directory.MemberType{Id: id, Type: directory.GroupType}
+		emails[group.Email] = memberType
+		for _, alias := range group.Aliases {
+			emails[alias] = memberType
+		}
+	}
+	return emails
+}
+
 func membersHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		members := dirSync.MemberIdToGroupIdsMapping()
-		if members == nil {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("{}"))
-		} else {
-			err := json.NewEncoder(w).Encode(members)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(fmt.Sprintf("Failed to marshal members json: %v\n", err)))
-				return
-			}
+		snapshot := dirSync.Snapshot()
+		if r.URL.Query().Get("transitive") == "true" {
+			writeSnapshotValue(w, r, snapshot.Hash, snapshot.Closure.Memberships)
+			return
 		}
+		writeSnapshotJson(w, r, snapshot.Hash, snapshot.Encoded.Members)
 	}
 }
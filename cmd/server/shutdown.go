@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/sirupsen/logrus"
+)
+
+// server is an API server that shuts down gracefully.
+type server interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+}
+
+// serve runs the servers until one fails or SIGINT/SIGTERM is received
+// and shuts down afterwards. The returned exit code is 0 for a clean shutdown
+// and 1 otherwise.
+func serve(servers []server, dirSync sync.DirSync, stopSync context.CancelFunc, timeout time.Duration) int {
+	exitCode := 0
+
+	serverErr := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv server) {
+			serverErr <- srv.ListenAndServe()
+		}(srv)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serverErr:
+		logrus.Errorf("Server failed: %v", err)
+		exitCode = 1
+	case sig := <-signals:
+		logrus.Infof("Received %v. Shutting down", sig)
+	}
+
+	if !shutdown(servers, dirSync, stopSync, timeout) {
+		exitCode = 1
+	}
+	return exitCode
+}
+
+// shutdown stops the sync loop through stopSync while the open connections
+// are drained. Both get the whole timeout, so a slow sync never shortens the
+// drain or the other way around. Afterwards the current directory is flushed
+// to disk. It reports whether everything completed in time.
+func shutdown(servers []server, dirSync sync.DirSync, stopSync context.CancelFunc, timeout time.Duration) bool {
+	stopSync()
+	syncStopped := make(chan bool, 1)
+	go func() {
+		select {
+		case <-dirSync.Done():
+			syncStopped <- true
+		case <-time.After(timeout):
+			logrus.Errorf("Sync loop did not stop within %v", timeout)
+			syncStopped <- false
+		}
+	}()
+
+	clean := drain(servers, timeout)
+	if !<-syncStopped {
+		clean = false
+	}
+
+	err := dirSync.Flush()
+	if err != nil {
+		logrus.Errorf("Failed to persist directory to disk: %v", err)
+		clean = false
+	}
+
+	logrus.Infof("Shutdown complete")
+	return clean
+}
+
+// drain shuts down all servers at once and reports whether their connections
+// were closed within the timeout.
+func drain(servers []server, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	drained := make(chan bool, len(servers))
+	for _, srv := range servers {
+		go func(srv server) {
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				logrus.Errorf("Failed to drain connections: %v", err)
+			}
+			drained <- err == nil
+		}(srv)
+	}
+
+	clean := true
+	for range servers {
+		if !<-drained {
+			clean = false
+		}
+	}
+	return clean
+}
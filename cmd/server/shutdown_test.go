@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/stretchr/testify/assert"
+)
+
+// stoppingSync is a directory whose sync loop stops once stopped is closed.
+type stoppingSync struct {
+	sync.DirSync
+	stopped chan struct{}
+	flushed bool
+}
+
+func (s *stoppingSync) Done() <-chan struct{} {
+	return s.stopped
+}
+
+func (s *stoppingSync) Flush() error {
+	s.flushed = true
+	return nil
+}
+
+func TestShutdownStopsSyncWhileDraining(t *testing.T) {
+	a := assert.New(t)
+
+	// The request only completes once the sync was stopped, which requires
+	// stopping the sync while the connections are drained
+	dirSync := &stoppingSync{stopped: make(chan struct{})}
+	handling := make(chan struct{})
+	apiServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(handling)
+		<-dirSync.stopped
+	})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	a.NoError(err)
+	go apiServer.Serve(listener)
+
+	response := make(chan error, 1)
+	go func() {
+		res, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			res.Body.Close()
+		}
+		response <- err
+	}()
+	<-handling
+
+	stopSync := func() { close(dirSync.stopped) }
+	a.True(shutdown([]server{apiServer}, dirSync, stopSync, time.Second))
+	a.NoError(<-response)
+	a.True(dirSync.flushed)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	a := assert.New(t)
+
+	dirSync := &stoppingSync{stopped: make(chan struct{})}
+	started := time.Now()
+	a.False(shutdown(nil, dirSync, func() {}, 50*time.Millisecond))
+	a.True(time.Since(started) < time.Second)
+	a.True(dirSync.flushed, "the directory is flushed even if the sync did not stop")
+}
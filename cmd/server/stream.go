@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/sirupsen/logrus"
+)
+
+const streamKeepAliveInterval = 30 * time.Second
+
+type snapshotEvent struct {
+	Generation uint64    `json:"generation"`
+	Hash       string    `json:"hash"`
+	Created    time.Time `json:"created"`
+}
+
+// streamHandler pushes server sent events. A snapshot event with the
+// generation as event id is sent for every published snapshot, preceded by a
+// change event for every change of the directory since the last snapshot
+// event. Clients resuming with Last-Event-ID receive the changes they missed
+// from the change log or a resync event if the log does not cover them
+// anymore. The stream ends when the client disconnects or shutdown is closed.
+func streamHandler(dirSync sync.DirSync, shutdown <-chan struct{}) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeJson(w, http.StatusInternalServerError, errorResponse{Error: "streaming is not supported"})
+			return
+		}
+
+		var last uint64
+		resume := false
+		if lastEventId := r.Header.Get("Last-Event-ID"); lastEventId != "" {
+			var err error
+			last, err = strconv.ParseUint(lastEventId, 10, 64)
+			if err != nil {
+				writeJson(w, http.StatusBadRequest, errorResponse{Error: "Last-Event-ID must be a generation"})
+				return
+			}
+			resume = true
+		}
+
+		query := r.URL.Query()
+		filter := sync.NewChangeFilter(query["group"], query["member"], dirSync.Snapshot().Emails)
+		watcher := sync.NewWatcher(dirSync, last, resume, filter)
+		defer watcher.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		send := func() bool {
+			changes, snapshot, resync := watcher.Next()
+			if resync != nil {
+				writeEvent(w, "", "resync", resync)
+				flusher.Flush()
+				return false
+			}
+			for _, change := range changes {
+				writeEvent(w, "", "change", change)
+			}
+			if snapshot != nil {
+				writeEvent(w, strconv.FormatUint(snapshot.Generation, 10), "snapshot", snapshotEvent{
+					Generation: snapshot.Generation,
+					Hash:       snapshot.Hash,
+					Created:    snapshot.Created,
+				})
+			}
+			flusher.Flush()
+			return true
+		}
+
+		if !send() {
+			return
+		}
+
+		keepAlive := time.NewTicker(streamKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-watcher.Notifications():
+				if !send() {
+					return
+				}
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			case <-shutdown:
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, id string, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logrus.Errorf("Failed to encode %v event: %v", event, err)
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %v\n", id)
+	}
+	fmt.Fprintf(w, "event: %v\ndata: %s\n\n", event, data)
+}
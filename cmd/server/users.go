@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/gorilla/mux"
+)
+
+func usersHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot := dirSync.Snapshot()
+		writeSnapshotJson(w, r, snapshot.Hash, snapshot.Encoded.Users)
+	}
+}
+
+func userHandler(dirSync sync.DirSync) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idOrEmail := mux.Vars(r)["idOrEmail"]
+		user, ok := dirSync.Snapshot().User(idOrEmail)
+		if !ok {
+			writeJson(w, http.StatusNotFound, errorResponse{Error: "user " + idOrEmail + " not found"})
+			return
+		}
+		writeJson(w, http.StatusOK, user)
+	}
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/fabzo/gcloud-directory-service/webhooks"
+)
+
+func webhooksHandler(dispatcher *webhooks.Dispatcher) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dispatcher == nil {
+			writeJson(w, http.StatusOK, []*webhooks.Status{})
+			return
+		}
+		writeJson(w, http.StatusOK, dispatcher.Status())
+	}
+}
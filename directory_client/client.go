@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
 	"io/ioutil"
+	"strconv"
 	"time"
 )
 
@@ -81,6 +82,98 @@ func (c *Client) SyncDirectory() error {
 	return nil
 }
 
+// maxPagedSyncAttempts limits how often a paged sync is restarted because the
+// directory changed while paging through it.
+const maxPagedSyncAttempts = 3
+
+type groupPage struct {
+	Generation uint64             `json:"generation"`
+	Groups     []*directory.Group `json:"groups"`
+	NextCursor string             `json:"next_cursor"`
+}
+
+// SyncDirectoryPaged updates the local directory copy like SyncDirectory, but
+// retrieves the groups in pages of the given size. This keeps every single
+// request small for large directories. If the directory changes while paging
+// through it the sync starts over, so the local copy is always consistent.
+func (c *Client) SyncDirectoryPaged(pageSize int) error {
+	for attempt := 0; attempt < maxPagedSyncAttempts; attempt++ {
+		groups, consistent, err := c.retrieveDirectoryPages(pageSize)
+		if err != nil {
+			return err
+		}
+		if !consistent {
+			continue
+		}
+
+		c.groups = groups
+		c.emailToMember = directory.ToEmailMemberMapping(groups)
+		c.memberIdToGroupIds = directory.ToMemberIdGroupIdsMapping(groups)
+		return nil
+	}
+	return fmt.Errorf("directory changed during all %d attempts to page through it", maxPagedSyncAttempts)
+}
+
+func (c *Client) retrieveDirectoryPages(pageSize int) (map[string]*directory.Group, bool, error) {
+	groups := map[string]*directory.Group{}
+	cursor := ""
+	var generation uint64
+
+	for first := true; first || cursor != ""; first = false {
+		page, err := c.retrieveDirectoryPage(pageSize, cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		if !first && page.Generation != generation {
+			return nil, false, nil
+		}
+		generation = page.Generation
+
+		for _, group := range page.Groups {
+			groups[group.Id] = group
+		}
+		cursor = page.NextCursor
+	}
+
+	return groups, true, nil
+}
+
+func (c *Client) retrieveDirectoryPage(pageSize int, cursor string) (*groupPage, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(pageSize))
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+
+	req, err := http.NewRequest("GET", c.url+"/api/groups/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("directory service request failed %d: %s", resp.StatusCode, string(data))
+	}
+
+	var page groupPage
+	err = json.Unmarshal(data, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
 func (c *Client) Directory() map[string]*directory.Group {
 	return c.groups
 }
@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func toGroup(group *directory.Group) *Group {
+	message := &Group{
+		Id:          group.Id,
+		Name:        group.Name,
+		Description: group.Description,
+		Email:       group.Email,
+		Etag:        group.ETag,
+		Aliases:     group.Aliases,
+		Stale:       group.Stale,
+		Members:     make([]*Member, 0, len(group.Members)),
+	}
+	for _, member := range group.Members {
+		message.Members = append(message.Members, toMember(member))
+	}
+	sort.Slice(message.Members, func(i, j int) bool {
+		return message.Members[i].Id < message.Members[j].Id
+	})
+	return message
+}
+
+func toMember(member *directory.Member) *Member {
+	return &Member{
+		Id:     member.Id,
+		Email:  member.Email,
+		Etag:   member.Etag,
+		Role:   member.Role,
+		Status: member.Status,
+		Type:   member.Type,
+	}
+}
+
+func toMemberGroup(group *sync.MemberGroup) *MemberGroup {
+	return &MemberGroup{
+		GroupId: group.GroupId,
+		Email:   group.Email,
+		Name:    group.Name,
+		Role:    group.Role,
+		Status:  group.Status,
+		Depth:   int32(group.Depth),
+	}
+}
+
+func toStatus(status *sync.Status) *Status {
+	return &Status{
+		Generation:       status.Generation,
+		Hash:             status.Hash,
+		LastSync:         toTimestamp(status.LastSync),
+		LastSyncDuration: ptypes.DurationProto(status.LastSyncDuration.Duration),
+		NextSync:         toTimestamp(status.NextSync),
+		LastFullSync:     toTimestamp(status.LastFullSync),
+		LastChangeSync:   toTimestamp(status.LastChangeSync),
+		SyncInProgress:   status.SyncInProgress,
+		Quarantined:      status.Quarantined,
+		KnownGroups:      int32(status.KnownGroups),
+		KnownUsers:       int32(status.KnownUsers),
+		ApiCalls:         status.ApiCalls,
+		Retries:          status.Retries,
+		SyncedUsers:      int32(status.SyncedUsers),
+		SyncedOrgUnits:   int32(status.SyncedOrgUnits),
+		SyncedAdminRoles: int32(status.SyncedAdminRoles),
+		SyncedDomains:    int32(status.SyncedDomains),
+		GroupCycles:      status.GroupCycles,
+	}
+}
+
+func toChange(change *sync.Change) *Change {
+	return &Change{
+		Generation:  change.Generation,
+		Time:        toTimestamp(change.Time),
+		Type:        change.Type,
+		GroupId:     change.GroupId,
+		GroupEmail:  change.GroupEmail,
+		MemberId:    change.MemberId,
+		MemberEmail: change.MemberEmail,
+		MemberType:  change.MemberType,
+		Old:         change.Old,
+		New:         change.New,
+	}
+}
+
+func toSnapshot(snapshot *sync.Snapshot) *Snapshot {
+	return &Snapshot{
+		Generation: snapshot.Generation,
+		Hash:       snapshot.Hash,
+		Created:    toTimestamp(snapshot.Created),
+	}
+}
+
+func toResync(resync *sync.ResyncRequired) *ResyncRequired {
+	return &ResyncRequired{
+		Since:            resync.Since,
+		OldestGeneration: resync.Oldest,
+		Generation:       resync.Generation,
+	}
+}
+
+// toTimestamp converts the time, leaving out unset times.
+func toTimestamp(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
@@ -0,0 +1,1233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: rpc/directory.proto
+
+/*
+Package rpc is a generated protocol buffer package.
+
+It is generated from these files:
+
+	rpc/directory.proto
+
+It has these top-level messages:
+
+	Member
+	Group
+	StatusRequest
+	Status
+	GetGroupRequest
+	ListGroupsRequest
+	GetMemberGroupsRequest
+	MemberGroup
+	GetMemberGroupsResponse
+	CheckMembershipRequest
+	CheckMembershipResponse
+	WatchChangesRequest
+	Change
+	Snapshot
+	ResyncRequired
+	WatchEvent
+*/
+package rpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/duration"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Member struct {
+	Id     string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Email  string `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
+	Etag   string `protobuf:"bytes,3,opt,name=etag" json:"etag,omitempty"`
+	Role   string `protobuf:"bytes,4,opt,name=role" json:"role,omitempty"`
+	Status string `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	Type   string `protobuf:"bytes,6,opt,name=type" json:"type,omitempty"`
+}
+
+func (m *Member) Reset()                    { *m = Member{} }
+func (m *Member) String() string            { return proto.CompactTextString(m) }
+func (*Member) ProtoMessage()               {}
+func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Member) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Member) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *Member) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
+func (m *Member) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *Member) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Member) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+type Group struct {
+	Id          string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Name        string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Description string   `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	Email       string   `protobuf:"bytes,4,opt,name=email" json:"email,omitempty"`
+	Etag        string   `protobuf:"bytes,5,opt,name=etag" json:"etag,omitempty"`
+	Aliases     []string `protobuf:"bytes,6,rep,name=aliases" json:"aliases,omitempty"`
+	// members are ordered by id
+	Members []*Member `protobuf:"bytes,7,rep,name=members" json:"members,omitempty"`
+	// stale is set if the members could not be retrieved during the last sync
+	Stale bool `protobuf:"varint,8,opt,name=stale" json:"stale,omitempty"`
+}
+
+func (m *Group) Reset()                    { *m = Group{} }
+func (m *Group) String() string            { return proto.CompactTextString(m) }
+func (*Group) ProtoMessage()               {}
+func (*Group) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Group) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Group) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Group) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Group) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *Group) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
+func (m *Group) GetAliases() []string {
+	if m != nil {
+		return m.Aliases
+	}
+	return nil
+}
+
+func (m *Group) GetMembers() []*Member {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *Group) GetStale() bool {
+	if m != nil {
+		return m.Stale
+	}
+	return false
+}
+
+type StatusRequest struct {
+}
+
+func (m *StatusRequest) Reset()                    { *m = StatusRequest{} }
+func (m *StatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()               {}
+func (*StatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type Status struct {
+	Generation       uint64                      `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Hash             string                      `protobuf:"bytes,2,opt,name=hash" json:"hash,omitempty"`
+	LastSync         *google_protobuf1.Timestamp `protobuf:"bytes,3,opt,name=last_sync,json=lastSync" json:"last_sync,omitempty"`
+	LastSyncDuration *google_protobuf.Duration   `protobuf:"bytes,4,opt,name=last_sync_duration,json=lastSyncDuration" json:"last_sync_duration,omitempty"`
+	NextSync         *google_protobuf1.Timestamp `protobuf:"bytes,5,opt,name=next_sync,json=nextSync" json:"next_sync,omitempty"`
+	LastFullSync     *google_protobuf1.Timestamp `protobuf:"bytes,6,opt,name=last_full_sync,json=lastFullSync" json:"last_full_sync,omitempty"`
+	LastChangeSync   *google_protobuf1.Timestamp `protobuf:"bytes,7,opt,name=last_change_sync,json=lastChangeSync" json:"last_change_sync,omitempty"`
+	SyncInProgress   bool                        `protobuf:"varint,8,opt,name=sync_in_progress,json=syncInProgress" json:"sync_in_progress,omitempty"`
+	Quarantined      bool                        `protobuf:"varint,9,opt,name=quarantined" json:"quarantined,omitempty"`
+	KnownGroups      int32                       `protobuf:"varint,10,opt,name=known_groups,json=knownGroups" json:"known_groups,omitempty"`
+	KnownUsers       int32                       `protobuf:"varint,11,opt,name=known_users,json=knownUsers" json:"known_users,omitempty"`
+	ApiCalls         int64                       `protobuf:"varint,12,opt,name=api_calls,json=apiCalls" json:"api_calls,omitempty"`
+	Retries          int64                       `protobuf:"varint,13,opt,name=retries" json:"retries,omitempty"`
+	SyncedUsers      int32                       `protobuf:"varint,14,opt,name=synced_users,json=syncedUsers" json:"synced_users,omitempty"`
+	SyncedOrgUnits   int32                       `protobuf:"varint,15,opt,name=synced_org_units,json=syncedOrgUnits" json:"synced_org_units,omitempty"`
+	SyncedAdminRoles int32                       `protobuf:"varint,16,opt,name=synced_admin_roles,json=syncedAdminRoles" json:"synced_admin_roles,omitempty"`
+	SyncedDomains    int32                       `protobuf:"varint,17,opt,name=synced_domains,json=syncedDomains" json:"synced_domains,omitempty"`
+	GroupCycles      []string                    `protobuf:"bytes,18,rep,name=group_cycles,json=groupCycles" json:"group_cycles,omitempty"`
+}
+
+func (m *Status) Reset()                    { *m = Status{} }
+func (m *Status) String() string            { return proto.CompactTextString(m) }
+func (*Status) ProtoMessage()               {}
+func (*Status) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Status) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *Status) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Status) GetLastSync() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.LastSync
+	}
+	return nil
+}
+
+func (m *Status) GetLastSyncDuration() *google_protobuf.Duration {
+	if m != nil {
+		return m.LastSyncDuration
+	}
+	return nil
+}
+
+func (m *Status) GetNextSync() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.NextSync
+	}
+	return nil
+}
+
+func (m *Status) GetLastFullSync() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.LastFullSync
+	}
+	return nil
+}
+
+func (m *Status) GetLastChangeSync() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.LastChangeSync
+	}
+	return nil
+}
+
+func (m *Status) GetSyncInProgress() bool {
+	if m != nil {
+		return m.SyncInProgress
+	}
+	return false
+}
+
+func (m *Status) GetQuarantined() bool {
+	if m != nil {
+		return m.Quarantined
+	}
+	return false
+}
+
+func (m *Status) GetKnownGroups() int32 {
+	if m != nil {
+		return m.KnownGroups
+	}
+	return 0
+}
+
+func (m *Status) GetKnownUsers() int32 {
+	if m != nil {
+		return m.KnownUsers
+	}
+	return 0
+}
+
+func (m *Status) GetApiCalls() int64 {
+	if m != nil {
+		return m.ApiCalls
+	}
+	return 0
+}
+
+func (m *Status) GetRetries() int64 {
+	if m != nil {
+		return m.Retries
+	}
+	return 0
+}
+
+func (m *Status) GetSyncedUsers() int32 {
+	if m != nil {
+		return m.SyncedUsers
+	}
+	return 0
+}
+
+func (m *Status) GetSyncedOrgUnits() int32 {
+	if m != nil {
+		return m.SyncedOrgUnits
+	}
+	return 0
+}
+
+func (m *Status) GetSyncedAdminRoles() int32 {
+	if m != nil {
+		return m.SyncedAdminRoles
+	}
+	return 0
+}
+
+func (m *Status) GetSyncedDomains() int32 {
+	if m != nil {
+		return m.SyncedDomains
+	}
+	return 0
+}
+
+func (m *Status) GetGroupCycles() []string {
+	if m != nil {
+		return m.GroupCycles
+	}
+	return nil
+}
+
+type GetGroupRequest struct {
+	Group string `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *GetGroupRequest) Reset()                    { *m = GetGroupRequest{} }
+func (m *GetGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetGroupRequest) ProtoMessage()               {}
+func (*GetGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetGroupRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+// ListGroupsRequest filters like /api/groups/search. Text matches are case
+// insensitive, member filters restrict the members of every group.
+type ListGroupsRequest struct {
+	Query        string `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+	Prefix       string `protobuf:"bytes,2,opt,name=prefix" json:"prefix,omitempty"`
+	Domain       string `protobuf:"bytes,3,opt,name=domain" json:"domain,omitempty"`
+	MemberRole   string `protobuf:"bytes,4,opt,name=member_role,json=memberRole" json:"member_role,omitempty"`
+	MemberStatus string `protobuf:"bytes,5,opt,name=member_status,json=memberStatus" json:"member_status,omitempty"`
+	MemberType   string `protobuf:"bytes,6,opt,name=member_type,json=memberType" json:"member_type,omitempty"`
+}
+
+func (m *ListGroupsRequest) Reset()                    { *m = ListGroupsRequest{} }
+func (m *ListGroupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListGroupsRequest) ProtoMessage()               {}
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ListGroupsRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *ListGroupsRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ListGroupsRequest) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *ListGroupsRequest) GetMemberRole() string {
+	if m != nil {
+		return m.MemberRole
+	}
+	return ""
+}
+
+func (m *ListGroupsRequest) GetMemberStatus() string {
+	if m != nil {
+		return m.MemberStatus
+	}
+	return ""
+}
+
+func (m *ListGroupsRequest) GetMemberType() string {
+	if m != nil {
+		return m.MemberType
+	}
+	return ""
+}
+
+type GetMemberGroupsRequest struct {
+	Member     string `protobuf:"bytes,1,opt,name=member" json:"member,omitempty"`
+	Transitive bool   `protobuf:"varint,2,opt,name=transitive" json:"transitive,omitempty"`
+}
+
+func (m *GetMemberGroupsRequest) Reset()                    { *m = GetMemberGroupsRequest{} }
+func (m *GetMemberGroupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetMemberGroupsRequest) ProtoMessage()               {}
+func (*GetMemberGroupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *GetMemberGroupsRequest) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *GetMemberGroupsRequest) GetTransitive() bool {
+	if m != nil {
+		return m.Transitive
+	}
+	return false
+}
+
+type MemberGroup struct {
+	GroupId string `protobuf:"bytes,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Email   string `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Role    string `protobuf:"bytes,4,opt,name=role" json:"role,omitempty"`
+	Status  string `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	Depth   int32  `protobuf:"varint,6,opt,name=depth" json:"depth,omitempty"`
+}
+
+func (m *MemberGroup) Reset()                    { *m = MemberGroup{} }
+func (m *MemberGroup) String() string            { return proto.CompactTextString(m) }
+func (*MemberGroup) ProtoMessage()               {}
+func (*MemberGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *MemberGroup) GetGroupId() string {
+	if m != nil {
+		return m.GroupId
+	}
+	return ""
+}
+
+func (m *MemberGroup) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *MemberGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *MemberGroup) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *MemberGroup) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *MemberGroup) GetDepth() int32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+type GetMemberGroupsResponse struct {
+	Generation uint64         `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Member     *Member        `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
+	Groups     []*MemberGroup `protobuf:"bytes,3,rep,name=groups" json:"groups,omitempty"`
+}
+
+func (m *GetMemberGroupsResponse) Reset()                    { *m = GetMemberGroupsResponse{} }
+func (m *GetMemberGroupsResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetMemberGroupsResponse) ProtoMessage()               {}
+func (*GetMemberGroupsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *GetMemberGroupsResponse) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *GetMemberGroupsResponse) GetMember() *Member {
+	if m != nil {
+		return m.Member
+	}
+	return nil
+}
+
+func (m *GetMemberGroupsResponse) GetGroups() []*MemberGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type CheckMembershipRequest struct {
+	Member           string   `protobuf:"bytes,1,opt,name=member" json:"member,omitempty"`
+	Group            string   `protobuf:"bytes,2,opt,name=group" json:"group,omitempty"`
+	Transitive       bool     `protobuf:"varint,3,opt,name=transitive" json:"transitive,omitempty"`
+	Roles            []string `protobuf:"bytes,4,rep,name=roles" json:"roles,omitempty"`
+	ExcludeSuspended bool     `protobuf:"varint,5,opt,name=exclude_suspended,json=excludeSuspended" json:"exclude_suspended,omitempty"`
+}
+
+func (m *CheckMembershipRequest) Reset()                    { *m = CheckMembershipRequest{} }
+func (m *CheckMembershipRequest) String() string            { return proto.CompactTextString(m) }
+func (*CheckMembershipRequest) ProtoMessage()               {}
+func (*CheckMembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CheckMembershipRequest) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *CheckMembershipRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *CheckMembershipRequest) GetTransitive() bool {
+	if m != nil {
+		return m.Transitive
+	}
+	return false
+}
+
+func (m *CheckMembershipRequest) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *CheckMembershipRequest) GetExcludeSuspended() bool {
+	if m != nil {
+		return m.ExcludeSuspended
+	}
+	return false
+}
+
+type CheckMembershipResponse struct {
+	Generation uint64 `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	IsMember   bool   `protobuf:"varint,2,opt,name=is_member,json=isMember" json:"is_member,omitempty"`
+	Depth      int32  `protobuf:"varint,3,opt,name=depth" json:"depth,omitempty"`
+}
+
+func (m *CheckMembershipResponse) Reset()                    { *m = CheckMembershipResponse{} }
+func (m *CheckMembershipResponse) String() string            { return proto.CompactTextString(m) }
+func (*CheckMembershipResponse) ProtoMessage()               {}
+func (*CheckMembershipResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *CheckMembershipResponse) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *CheckMembershipResponse) GetIsMember() bool {
+	if m != nil {
+		return m.IsMember
+	}
+	return false
+}
+
+func (m *CheckMembershipResponse) GetDepth() int32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+// WatchChangesRequest resumes after the generation since, which is the last
+// snapshot the client has seen. Without since the stream starts with the
+// current snapshot. Groups and members restrict the change events.
+type WatchChangesRequest struct {
+	Since   uint64   `protobuf:"varint,1,opt,name=since" json:"since,omitempty"`
+	Groups  []string `protobuf:"bytes,2,rep,name=groups" json:"groups,omitempty"`
+	Members []string `protobuf:"bytes,3,rep,name=members" json:"members,omitempty"`
+}
+
+func (m *WatchChangesRequest) Reset()                    { *m = WatchChangesRequest{} }
+func (m *WatchChangesRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchChangesRequest) ProtoMessage()               {}
+func (*WatchChangesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *WatchChangesRequest) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *WatchChangesRequest) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *WatchChangesRequest) GetMembers() []string {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+type Change struct {
+	Generation  uint64                      `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Time        *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=time" json:"time,omitempty"`
+	Type        string                      `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
+	GroupId     string                      `protobuf:"bytes,4,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	GroupEmail  string                      `protobuf:"bytes,5,opt,name=group_email,json=groupEmail" json:"group_email,omitempty"`
+	MemberId    string                      `protobuf:"bytes,6,opt,name=member_id,json=memberId" json:"member_id,omitempty"`
+	MemberEmail string                      `protobuf:"bytes,7,opt,name=member_email,json=memberEmail" json:"member_email,omitempty"`
+	MemberType  string                      `protobuf:"bytes,8,opt,name=member_type,json=memberType" json:"member_type,omitempty"`
+	Old         string                      `protobuf:"bytes,9,opt,name=old" json:"old,omitempty"`
+	New         string                      `protobuf:"bytes,10,opt,name=new" json:"new,omitempty"`
+}
+
+func (m *Change) Reset()                    { *m = Change{} }
+func (m *Change) String() string            { return proto.CompactTextString(m) }
+func (*Change) ProtoMessage()               {}
+func (*Change) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Change) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *Change) GetTime() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Change) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Change) GetGroupId() string {
+	if m != nil {
+		return m.GroupId
+	}
+	return ""
+}
+
+func (m *Change) GetGroupEmail() string {
+	if m != nil {
+		return m.GroupEmail
+	}
+	return ""
+}
+
+func (m *Change) GetMemberId() string {
+	if m != nil {
+		return m.MemberId
+	}
+	return ""
+}
+
+func (m *Change) GetMemberEmail() string {
+	if m != nil {
+		return m.MemberEmail
+	}
+	return ""
+}
+
+func (m *Change) GetMemberType() string {
+	if m != nil {
+		return m.MemberType
+	}
+	return ""
+}
+
+func (m *Change) GetOld() string {
+	if m != nil {
+		return m.Old
+	}
+	return ""
+}
+
+func (m *Change) GetNew() string {
+	if m != nil {
+		return m.New
+	}
+	return ""
+}
+
+type Snapshot struct {
+	Generation uint64                      `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Hash       string                      `protobuf:"bytes,2,opt,name=hash" json:"hash,omitempty"`
+	Created    *google_protobuf1.Timestamp `protobuf:"bytes,3,opt,name=created" json:"created,omitempty"`
+}
+
+func (m *Snapshot) Reset()                    { *m = Snapshot{} }
+func (m *Snapshot) String() string            { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()               {}
+func (*Snapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *Snapshot) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func (m *Snapshot) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Snapshot) GetCreated() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+type ResyncRequired struct {
+	Since            uint64 `protobuf:"varint,1,opt,name=since" json:"since,omitempty"`
+	OldestGeneration uint64 `protobuf:"varint,2,opt,name=oldest_generation,json=oldestGeneration" json:"oldest_generation,omitempty"`
+	Generation       uint64 `protobuf:"varint,3,opt,name=generation" json:"generation,omitempty"`
+}
+
+func (m *ResyncRequired) Reset()                    { *m = ResyncRequired{} }
+func (m *ResyncRequired) String() string            { return proto.CompactTextString(m) }
+func (*ResyncRequired) ProtoMessage()               {}
+func (*ResyncRequired) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ResyncRequired) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *ResyncRequired) GetOldestGeneration() uint64 {
+	if m != nil {
+		return m.OldestGeneration
+	}
+	return 0
+}
+
+func (m *ResyncRequired) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+// WatchEvent holds exactly one of change, snapshot and resync. The stream
+// ends after a resync event, the directory has to be retrieved again.
+type WatchEvent struct {
+	Change   *Change         `protobuf:"bytes,1,opt,name=change" json:"change,omitempty"`
+	Snapshot *Snapshot       `protobuf:"bytes,2,opt,name=snapshot" json:"snapshot,omitempty"`
+	Resync   *ResyncRequired `protobuf:"bytes,3,opt,name=resync" json:"resync,omitempty"`
+}
+
+func (m *WatchEvent) Reset()                    { *m = WatchEvent{} }
+func (m *WatchEvent) String() string            { return proto.CompactTextString(m) }
+func (*WatchEvent) ProtoMessage()               {}
+func (*WatchEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *WatchEvent) GetChange() *Change {
+	if m != nil {
+		return m.Change
+	}
+	return nil
+}
+
+func (m *WatchEvent) GetSnapshot() *Snapshot {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+func (m *WatchEvent) GetResync() *ResyncRequired {
+	if m != nil {
+		return m.Resync
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Member)(nil), "directory.Member")
+	proto.RegisterType((*Group)(nil), "directory.Group")
+	proto.RegisterType((*StatusRequest)(nil), "directory.StatusRequest")
+	proto.RegisterType((*Status)(nil), "directory.Status")
+	proto.RegisterType((*GetGroupRequest)(nil), "directory.GetGroupRequest")
+	proto.RegisterType((*ListGroupsRequest)(nil), "directory.ListGroupsRequest")
+	proto.RegisterType((*GetMemberGroupsRequest)(nil), "directory.GetMemberGroupsRequest")
+	proto.RegisterType((*MemberGroup)(nil), "directory.MemberGroup")
+	proto.RegisterType((*GetMemberGroupsResponse)(nil), "directory.GetMemberGroupsResponse")
+	proto.RegisterType((*CheckMembershipRequest)(nil), "directory.CheckMembershipRequest")
+	proto.RegisterType((*CheckMembershipResponse)(nil), "directory.CheckMembershipResponse")
+	proto.RegisterType((*WatchChangesRequest)(nil), "directory.WatchChangesRequest")
+	proto.RegisterType((*Change)(nil), "directory.Change")
+	proto.RegisterType((*Snapshot)(nil), "directory.Snapshot")
+	proto.RegisterType((*ResyncRequired)(nil), "directory.ResyncRequired")
+	proto.RegisterType((*WatchEvent)(nil), "directory.WatchEvent")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for DirectoryService service
+
+type DirectoryServiceClient interface {
+	GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*Status, error)
+	GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error)
+	// ListGroups streams the groups matching the filters ordered by email.
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (DirectoryService_ListGroupsClient, error)
+	GetMemberGroups(ctx context.Context, in *GetMemberGroupsRequest, opts ...grpc.CallOption) (*GetMemberGroupsResponse, error)
+	CheckMembership(ctx context.Context, in *CheckMembershipRequest, opts ...grpc.CallOption) (*CheckMembershipResponse, error)
+	// WatchChanges streams the changes of every published snapshot followed
+	// by the snapshot itself.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (DirectoryService_WatchChangesClient, error)
+}
+
+type directoryServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDirectoryServiceClient(cc *grpc.ClientConn) DirectoryServiceClient {
+	return &directoryServiceClient{cc}
+}
+
+func (c *directoryServiceClient) GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := grpc.Invoke(ctx, "/directory.DirectoryService/GetStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *directoryServiceClient) GetGroup(ctx context.Context, in *GetGroupRequest, opts ...grpc.CallOption) (*Group, error) {
+	out := new(Group)
+	err := grpc.Invoke(ctx, "/directory.DirectoryService/GetGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *directoryServiceClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (DirectoryService_ListGroupsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_DirectoryService_serviceDesc.Streams[0], c.cc, "/directory.DirectoryService/ListGroups", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &directoryServiceListGroupsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DirectoryService_ListGroupsClient interface {
+	Recv() (*Group, error)
+	grpc.ClientStream
+}
+
+type directoryServiceListGroupsClient struct {
+	grpc.ClientStream
+}
+
+func (x *directoryServiceListGroupsClient) Recv() (*Group, error) {
+	m := new(Group)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *directoryServiceClient) GetMemberGroups(ctx context.Context, in *GetMemberGroupsRequest, opts ...grpc.CallOption) (*GetMemberGroupsResponse, error) {
+	out := new(GetMemberGroupsResponse)
+	err := grpc.Invoke(ctx, "/directory.DirectoryService/GetMemberGroups", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *directoryServiceClient) CheckMembership(ctx context.Context, in *CheckMembershipRequest, opts ...grpc.CallOption) (*CheckMembershipResponse, error) {
+	out := new(CheckMembershipResponse)
+	err := grpc.Invoke(ctx, "/directory.DirectoryService/CheckMembership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *directoryServiceClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (DirectoryService_WatchChangesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_DirectoryService_serviceDesc.Streams[1], c.cc, "/directory.DirectoryService/WatchChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &directoryServiceWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DirectoryService_WatchChangesClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type directoryServiceWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *directoryServiceWatchChangesClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for DirectoryService service
+
+type DirectoryServiceServer interface {
+	GetStatus(context.Context, *StatusRequest) (*Status, error)
+	GetGroup(context.Context, *GetGroupRequest) (*Group, error)
+	// ListGroups streams the groups matching the filters ordered by email.
+	ListGroups(*ListGroupsRequest, DirectoryService_ListGroupsServer) error
+	GetMemberGroups(context.Context, *GetMemberGroupsRequest) (*GetMemberGroupsResponse, error)
+	CheckMembership(context.Context, *CheckMembershipRequest) (*CheckMembershipResponse, error)
+	// WatchChanges streams the changes of every published snapshot followed
+	// by the snapshot itself.
+	WatchChanges(*WatchChangesRequest, DirectoryService_WatchChangesServer) error
+}
+
+func RegisterDirectoryServiceServer(s *grpc.Server, srv DirectoryServiceServer) {
+	s.RegisterService(&_DirectoryService_serviceDesc, srv)
+}
+
+func _DirectoryService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DirectoryServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/directory.DirectoryService/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DirectoryServiceServer).GetStatus(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DirectoryService_GetGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DirectoryServiceServer).GetGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/directory.DirectoryService/GetGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DirectoryServiceServer).GetGroup(ctx, req.(*GetGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DirectoryService_ListGroups_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListGroupsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DirectoryServiceServer).ListGroups(m, &directoryServiceListGroupsServer{stream})
+}
+
+type DirectoryService_ListGroupsServer interface {
+	Send(*Group) error
+	grpc.ServerStream
+}
+
+type directoryServiceListGroupsServer struct {
+	grpc.ServerStream
+}
+
+func (x *directoryServiceListGroupsServer) Send(m *Group) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DirectoryService_GetMemberGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMemberGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DirectoryServiceServer).GetMemberGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/directory.DirectoryService/GetMemberGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DirectoryServiceServer).GetMemberGroups(ctx, req.(*GetMemberGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DirectoryService_CheckMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckMembershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DirectoryServiceServer).CheckMembership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/directory.DirectoryService/CheckMembership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DirectoryServiceServer).CheckMembership(ctx, req.(*CheckMembershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DirectoryService_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DirectoryServiceServer).WatchChanges(m, &directoryServiceWatchChangesServer{stream})
+}
+
+type DirectoryService_WatchChangesServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type directoryServiceWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *directoryServiceWatchChangesServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DirectoryService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "directory.DirectoryService",
+	HandlerType: (*DirectoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _DirectoryService_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetGroup",
+			Handler:    _DirectoryService_GetGroup_Handler,
+		},
+		{
+			MethodName: "GetMemberGroups",
+			Handler:    _DirectoryService_GetMemberGroups_Handler,
+		},
+		{
+			MethodName: "CheckMembership",
+			Handler:    _DirectoryService_CheckMembership_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListGroups",
+			Handler:       _DirectoryService_ListGroups_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _DirectoryService_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rpc/directory.proto",
+}
+
+func init() { proto.RegisterFile("rpc/directory.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 1291 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdd, 0x8e, 0xdb, 0x44,
+	0x14, 0x96, 0xe3, 0xfc, 0x38, 0x27, 0xfb, 0x93, 0x9d, 0x96, 0xad, 0x9b, 0xa2, 0x36, 0x35, 0x42,
+	0x04, 0x15, 0x65, 0xcb, 0x82, 0x44, 0xaf, 0x10, 0xb0, 0x5b, 0xa2, 0x4a, 0x54, 0x54, 0xde, 0x56,
+	0x54, 0x48, 0xc8, 0x72, 0xed, 0x69, 0x32, 0xaa, 0x63, 0xbb, 0x9e, 0x49, 0xdb, 0xbd, 0xe5, 0x96,
+	0x5b, 0xee, 0x78, 0x02, 0x2e, 0x78, 0x07, 0x24, 0x5e, 0x81, 0x07, 0x42, 0x73, 0xce, 0x38, 0x3b,
+	0x49, 0x96, 0xa6, 0x70, 0xe7, 0xf3, 0xcd, 0x37, 0xc7, 0x67, 0xce, 0xcf, 0x37, 0x03, 0x57, 0xaa,
+	0x32, 0x39, 0x4a, 0x45, 0xc5, 0x13, 0x55, 0x54, 0xe7, 0xe3, 0xb2, 0x2a, 0x54, 0xc1, 0xba, 0x4b,
+	0x60, 0x70, 0x73, 0x5a, 0x14, 0xd3, 0x8c, 0x1f, 0xe1, 0xc2, 0xb3, 0xc5, 0xf3, 0xa3, 0x74, 0x51,
+	0xc5, 0x4a, 0x14, 0x39, 0x51, 0x07, 0xb7, 0xd6, 0xd7, 0x95, 0x98, 0x73, 0xa9, 0xe2, 0x79, 0x49,
+	0x84, 0xe0, 0x67, 0x07, 0xda, 0x0f, 0xf9, 0xfc, 0x19, 0xaf, 0xd8, 0x1e, 0x34, 0x44, 0xea, 0x3b,
+	0x43, 0x67, 0xd4, 0x0d, 0x1b, 0x22, 0x65, 0x57, 0xa1, 0xc5, 0xe7, 0xb1, 0xc8, 0xfc, 0x06, 0x42,
+	0x64, 0x30, 0x06, 0x4d, 0xae, 0xe2, 0xa9, 0xef, 0x22, 0x88, 0xdf, 0x1a, 0xab, 0x8a, 0x8c, 0xfb,
+	0x4d, 0xc2, 0xf4, 0x37, 0x3b, 0x84, 0xb6, 0x54, 0xb1, 0x5a, 0x48, 0xbf, 0x85, 0xa8, 0xb1, 0x34,
+	0x57, 0x9d, 0x97, 0xdc, 0x6f, 0x13, 0x57, 0x7f, 0x07, 0x7f, 0x3b, 0xd0, 0x9a, 0x54, 0xc5, 0xa2,
+	0xdc, 0x88, 0x81, 0x41, 0x33, 0x8f, 0xe7, 0xdc, 0x84, 0x80, 0xdf, 0x6c, 0x08, 0xbd, 0x94, 0xcb,
+	0xa4, 0x12, 0xa5, 0x3e, 0xa8, 0x09, 0xc4, 0x86, 0x2e, 0x22, 0x6f, 0x5e, 0x16, 0x79, 0xcb, 0x8a,
+	0xdc, 0x87, 0x4e, 0x9c, 0x89, 0x58, 0x72, 0xe9, 0xb7, 0x87, 0xee, 0xa8, 0x1b, 0xd6, 0x26, 0xbb,
+	0x03, 0x9d, 0x39, 0xe6, 0x45, 0xfa, 0x9d, 0xa1, 0x3b, 0xea, 0x1d, 0x1f, 0x8c, 0x2f, 0xea, 0x40,
+	0x19, 0x0b, 0x6b, 0x86, 0xfe, 0xa1, 0x54, 0x71, 0xc6, 0x7d, 0x6f, 0xe8, 0x8c, 0xbc, 0x90, 0x8c,
+	0x60, 0x1f, 0x76, 0xcf, 0xf0, 0xd0, 0x21, 0x7f, 0xb9, 0xe0, 0x52, 0x05, 0xbf, 0xb4, 0xa1, 0x4d,
+	0x08, 0xbb, 0x09, 0x30, 0xe5, 0x39, 0xa7, 0x62, 0xe1, 0x81, 0x9b, 0xa1, 0x85, 0xe8, 0x60, 0x67,
+	0xb1, 0x9c, 0xd5, 0x07, 0xd7, 0xdf, 0xec, 0x0b, 0xe8, 0x66, 0xb1, 0x54, 0x91, 0x3c, 0xcf, 0x13,
+	0x3c, 0x76, 0xef, 0x78, 0x30, 0xa6, 0x02, 0x8f, 0xeb, 0x02, 0x8f, 0x1f, 0xd7, 0x05, 0x0e, 0x3d,
+	0x4d, 0x3e, 0x3b, 0xcf, 0x13, 0x36, 0x01, 0xb6, 0xdc, 0x18, 0xd5, 0x1d, 0x82, 0xc9, 0xe9, 0x1d,
+	0x5f, 0xdf, 0xf0, 0x70, 0x6a, 0x08, 0x61, 0xbf, 0x76, 0x50, 0x23, 0x3a, 0x82, 0x9c, 0xbf, 0x31,
+	0x11, 0xb4, 0xb6, 0x47, 0xa0, 0xc9, 0x18, 0xc1, 0x57, 0xb0, 0x87, 0x11, 0x3c, 0x5f, 0x64, 0x19,
+	0xed, 0x6e, 0x6f, 0xdd, 0xbd, 0xa3, 0x77, 0x7c, 0xbb, 0xc8, 0x32, 0xf4, 0x70, 0x0a, 0x18, 0x4e,
+	0x94, 0xcc, 0xe2, 0x7c, 0xca, 0xc9, 0x47, 0x67, 0xab, 0x0f, 0xfc, 0xeb, 0x09, 0x6e, 0x41, 0x2f,
+	0x23, 0xe8, 0x63, 0x12, 0x44, 0x1e, 0x95, 0x55, 0x31, 0xad, 0xb8, 0x94, 0xa6, 0x66, 0x7b, 0x1a,
+	0x7f, 0x90, 0x3f, 0x32, 0xa8, 0xee, 0xb2, 0x97, 0x8b, 0xb8, 0x8a, 0x73, 0x25, 0x72, 0x9e, 0xfa,
+	0x5d, 0x24, 0xd9, 0x10, 0xbb, 0x0d, 0x3b, 0x2f, 0xf2, 0xe2, 0x75, 0x1e, 0x4d, 0x75, 0xeb, 0x4a,
+	0x1f, 0x86, 0xce, 0xa8, 0x15, 0xf6, 0x10, 0xc3, 0x6e, 0x96, 0xec, 0x16, 0x90, 0x19, 0x2d, 0xa4,
+	0x6e, 0xa4, 0x1e, 0x32, 0x00, 0xa1, 0x27, 0x1a, 0x61, 0x37, 0xa0, 0x1b, 0x97, 0x22, 0x4a, 0xe2,
+	0x2c, 0x93, 0xfe, 0xce, 0xd0, 0x19, 0xb9, 0xa1, 0x17, 0x97, 0xe2, 0x44, 0xdb, 0xba, 0x39, 0x2b,
+	0xae, 0x2a, 0xc1, 0xa5, 0xbf, 0x8b, 0x4b, 0xb5, 0xa9, 0x7f, 0xad, 0xc3, 0xe5, 0xa9, 0x71, 0xbc,
+	0x47, 0xbf, 0x26, 0x8c, 0x3c, 0x9b, 0x93, 0xf2, 0x34, 0x2a, 0xaa, 0x69, 0xb4, 0xc8, 0x85, 0x92,
+	0xfe, 0x3e, 0xd2, 0xf6, 0x08, 0xff, 0xbe, 0x9a, 0x3e, 0xd1, 0x28, 0xfb, 0x04, 0x98, 0x61, 0xc6,
+	0xe9, 0x5c, 0xe4, 0x91, 0x1e, 0x5f, 0xe9, 0xf7, 0x91, 0x6b, 0x7c, 0x7c, 0xad, 0x17, 0x42, 0x8d,
+	0xb3, 0x0f, 0xc1, 0xec, 0x8f, 0xd2, 0x62, 0x1e, 0x8b, 0x5c, 0xfa, 0x07, 0xc8, 0xdc, 0x25, 0xf4,
+	0x94, 0x40, 0x1d, 0x21, 0xa6, 0x25, 0x4a, 0xce, 0x13, 0xed, 0x8e, 0xe1, 0x74, 0xf5, 0x10, 0x3b,
+	0x41, 0x28, 0xf8, 0x08, 0xf6, 0x27, 0x5c, 0x61, 0xa6, 0xcc, 0x80, 0xe8, 0x39, 0x42, 0x86, 0x51,
+	0x00, 0x32, 0x82, 0x3f, 0x1d, 0x38, 0xf8, 0x4e, 0x48, 0xa2, 0x4a, 0x8b, 0xfb, 0x72, 0xc1, 0xab,
+	0xf3, 0x9a, 0x8b, 0x86, 0x96, 0x9d, 0xb2, 0xe2, 0xcf, 0xc5, 0x1b, 0x33, 0x39, 0xc6, 0xd2, 0x38,
+	0xc5, 0x6b, 0xf4, 0xc2, 0x58, 0xba, 0x42, 0x34, 0xc4, 0x91, 0xa5, 0x60, 0x40, 0x90, 0x3e, 0x30,
+	0xfb, 0x00, 0x76, 0x0d, 0x61, 0x45, 0xce, 0x76, 0x08, 0x34, 0xd3, 0x7c, 0xe1, 0xc5, 0xd2, 0x36,
+	0xe3, 0xe5, 0xb1, 0x56, 0xb8, 0x47, 0x70, 0x38, 0xe1, 0x8a, 0x64, 0x63, 0xf5, 0x18, 0x87, 0xd0,
+	0x26, 0x9e, 0x39, 0x87, 0xb1, 0xb4, 0x40, 0xa8, 0x2a, 0xce, 0xa5, 0x50, 0xe2, 0x15, 0xe9, 0x9f,
+	0x17, 0x5a, 0x48, 0xf0, 0xab, 0x03, 0x3d, 0xcb, 0x1f, 0xbb, 0x0e, 0x1e, 0x25, 0x7c, 0xa9, 0x9f,
+	0x1d, 0xb4, 0x1f, 0xbc, 0x45, 0xc8, 0x51, 0x5a, 0x5d, 0x4b, 0x5a, 0xff, 0x8b, 0x90, 0x5f, 0x85,
+	0x56, 0xca, 0x4b, 0x35, 0xc3, 0xd3, 0xb6, 0x42, 0x32, 0x74, 0x58, 0xd7, 0x36, 0x4e, 0x2a, 0xcb,
+	0x22, 0x97, 0x7c, 0xab, 0xe6, 0x7d, 0xbc, 0x4c, 0x45, 0x63, 0xe8, 0x5c, 0xae, 0xb8, 0x75, 0x76,
+	0xc6, 0xd0, 0x36, 0x53, 0xe7, 0xa2, 0x38, 0x1f, 0x6e, 0x50, 0xa9, 0xaf, 0x0c, 0x2b, 0xf8, 0xdd,
+	0x81, 0xc3, 0x93, 0x19, 0x4f, 0x5e, 0xd0, 0xa2, 0x9c, 0x89, 0x72, 0x5b, 0x01, 0x96, 0xbd, 0xd8,
+	0xb0, 0x7a, 0x71, 0xad, 0x2c, 0xee, 0x7a, 0x59, 0xf4, 0x2e, 0x9a, 0x9f, 0x26, 0x36, 0x3c, 0x19,
+	0xec, 0x0e, 0x1c, 0xf0, 0x37, 0x49, 0xb6, 0x48, 0x79, 0x24, 0x17, 0xb2, 0xe4, 0x79, 0xca, 0x53,
+	0x4c, 0xa7, 0x17, 0xf6, 0xcd, 0xc2, 0x59, 0x8d, 0x07, 0x19, 0x5c, 0xdb, 0x08, 0xf5, 0x1d, 0x33,
+	0x78, 0x03, 0xba, 0x42, 0x46, 0x56, 0x12, 0xbd, 0xd0, 0x13, 0xf2, 0xe1, 0xf2, 0x40, 0x54, 0x30,
+	0xd7, 0x2e, 0xd8, 0x4f, 0x70, 0xe5, 0x87, 0x58, 0x25, 0x33, 0x12, 0x49, 0x7b, 0xba, 0xa4, 0xc8,
+	0x13, 0x6e, 0x7e, 0x42, 0x86, 0xce, 0x95, 0x49, 0x7b, 0x03, 0x8f, 0x67, 0x2c, 0xad, 0x54, 0xf5,
+	0x65, 0xe9, 0xd2, 0x35, 0x6a, 0xcc, 0xe0, 0x8f, 0x06, 0xb4, 0xc9, 0xf5, 0xd6, 0xe0, 0xc7, 0xd0,
+	0xd4, 0xaf, 0x13, 0xbf, 0xb1, 0x55, 0xd5, 0x91, 0xb7, 0x7c, 0x49, 0xb8, 0x17, 0x2f, 0x89, 0x95,
+	0x29, 0x68, 0xae, 0x4e, 0xc1, 0x2d, 0x20, 0xf5, 0x89, 0x68, 0x16, 0xa8, 0x99, 0x01, 0xa1, 0xfb,
+	0x1a, 0xd1, 0xc9, 0x33, 0x43, 0x2c, 0x52, 0x33, 0xc2, 0x1e, 0x01, 0x0f, 0x50, 0xec, 0xcd, 0x22,
+	0x6d, 0xef, 0xd0, 0xab, 0x83, 0x30, 0xda, 0xbf, 0x26, 0x02, 0xde, 0xba, 0x08, 0xb0, 0x3e, 0xb8,
+	0x45, 0x46, 0x57, 0x49, 0x37, 0xd4, 0x9f, 0x1a, 0xc9, 0xf9, 0x6b, 0xbc, 0x39, 0xba, 0xa1, 0xfe,
+	0x0c, 0x14, 0x78, 0x67, 0x79, 0x5c, 0xca, 0x59, 0xa1, 0xfe, 0xd7, 0x1b, 0xe1, 0x73, 0xe8, 0x24,
+	0x15, 0x8f, 0x15, 0x4f, 0xdf, 0xe1, 0x85, 0x50, 0x53, 0x03, 0x09, 0x7b, 0x21, 0xd7, 0x02, 0xae,
+	0xcb, 0x2f, 0x2a, 0x9e, 0xfe, 0x4b, 0xfd, 0xef, 0xc0, 0x41, 0x91, 0xa5, 0x5c, 0xaa, 0xc8, 0x0a,
+	0xac, 0x81, 0x8c, 0x3e, 0x2d, 0x4c, 0x2e, 0xc2, 0x5b, 0x0d, 0xdf, 0x5d, 0x0f, 0x3f, 0xf8, 0xcd,
+	0x01, 0xc0, 0xd6, 0xbb, 0xff, 0x8a, 0xe7, 0x4a, 0x4f, 0x3f, 0xdd, 0xed, 0xbe, 0xb3, 0x31, 0xfd,
+	0xd4, 0x41, 0xa1, 0x21, 0xb0, 0x23, 0xf0, 0xa4, 0x49, 0x92, 0xe9, 0x96, 0x2b, 0x16, 0xb9, 0xce,
+	0x5f, 0xb8, 0x24, 0xb1, 0x4f, 0xa1, 0x5d, 0x71, 0xeb, 0xd9, 0x74, 0xdd, 0xa2, 0xaf, 0x1e, 0x3c,
+	0x34, 0xc4, 0xe3, 0xbf, 0x5c, 0xe8, 0x9f, 0xd6, 0xa4, 0x33, 0x5e, 0xbd, 0x12, 0x09, 0x67, 0xf7,
+	0xa0, 0x3b, 0xe1, 0xca, 0x88, 0xbe, 0x6f, 0xff, 0xd3, 0x7e, 0xe7, 0x0d, 0x0e, 0x36, 0x56, 0xd8,
+	0x3d, 0xf0, 0xea, 0xcb, 0x8e, 0x0d, 0xac, 0xe5, 0xb5, 0x1b, 0x70, 0xd0, 0xb7, 0xd7, 0x90, 0xfd,
+	0x25, 0xc0, 0xc5, 0xe5, 0xc7, 0xde, 0xb7, 0xd6, 0x37, 0xee, 0xc4, 0xcd, 0xdd, 0x77, 0x1d, 0xf6,
+	0x14, 0xaf, 0x59, 0x5b, 0x90, 0xd9, 0xed, 0xd5, 0x00, 0x2e, 0xb9, 0x96, 0x06, 0xc1, 0xdb, 0x28,
+	0x46, 0x8d, 0x9e, 0xc2, 0xfe, 0x9a, 0x50, 0xad, 0x78, 0xbe, 0x5c, 0x6f, 0x07, 0xc1, 0xdb, 0x28,
+	0xc6, 0xf3, 0x04, 0x76, 0x6c, 0x51, 0x62, 0x37, 0xad, 0x3d, 0x97, 0xa8, 0xd5, 0xe0, 0xbd, 0xf5,
+	0x75, 0x6c, 0xa9, 0xbb, 0xce, 0x37, 0xad, 0x1f, 0xdd, 0xaa, 0x4c, 0x9e, 0xb5, 0xb1, 0xf9, 0x3f,
+	0xfb, 0x67, 0x00, 0xde, 0x3e, 0x39, 0x2e, 0x4f, 0x0d, 0x00, 0x00,
+}
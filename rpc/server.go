@@ -0,0 +1,21 @@
+package rpc
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync"
+)
+
+// Server implements the DirectoryService generated from directory.proto on
+// top of the published snapshots.
+type Server struct {
+	dirSync  sync.DirSync
+	shutdown <-chan struct{}
+}
+
+// NewServer creates the service for the directory. Streaming calls end when
+// shutdown is closed.
+func NewServer(dirSync sync.DirSync, shutdown <-chan struct{}) *Server {
+	return &Server{
+		dirSync:  dirSync,
+		shutdown: shutdown,
+	}
+}
@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testDirectory = `{
+	"g1": {"id": "g1", "name": "Admins", "email": "admins@your.org", "members": {
+		"u1": {"id": "u1", "email": "u1@your.org", "role": "OWNER", "type": "USER"},
+		"g2": {"id": "g2", "email": "team@your.org", "role": "MEMBER", "type": "GROUP"}
+	}},
+	"g2": {"id": "g2", "name": "Team", "email": "team@your.org", "members": {
+		"u2": {"id": "u2", "email": "u2@your.org", "role": "MEMBER", "type": "USER"}
+	}}
+}`
+
+// newTestClient serves the test directory and returns a client for it
+// together with a function that stops the server.
+func newTestClient(t *testing.T) (DirectoryServiceClient, func()) {
+	dir, err := ioutil.TempDir("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "directory.json")
+	err = ioutil.WriteFile(file, []byte(testDirectory), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirSync, err := sync.Mock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterDirectoryServiceServer(server, NewServer(dirSync, make(chan struct{})))
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDirectoryServiceClient(conn), func() {
+		conn.Close()
+		server.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestUnaryCalls(t *testing.T) {
+	a := assert.New(t)
+	client, stop := newTestClient(t)
+	defer stop()
+	ctx := context.Background()
+
+	group, err := client.GetGroup(ctx, &GetGroupRequest{Group: "Admins@your.org"})
+	a.NoError(err)
+	a.Equal("g1", group.Id)
+	a.Len(group.Members, 2)
+	a.Equal("g2", group.Members[0].Id)
+
+	_, err = client.GetGroup(ctx, &GetGroupRequest{Group: "unknown@your.org"})
+	notFound, _ := status.FromError(err)
+	a.Equal(codes.NotFound, notFound.Code())
+	a.Equal("group unknown@your.org not found", notFound.Message())
+
+	check, err := client.CheckMembership(ctx, &CheckMembershipRequest{Member: "u2@your.org", Group: "g1", Transitive: true})
+	a.NoError(err)
+	a.True(check.IsMember)
+	a.EqualValues(2, check.Depth)
+	a.EqualValues(1, check.Generation)
+
+	_, err = client.CheckMembership(ctx, &CheckMembershipRequest{Member: "u2@your.org"})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+// receiveAll reads the messages of a stream until it ends and returns the
+// final status.
+func receiveAll(recv func() (interface{}, error)) ([]interface{}, error) {
+	var messages []interface{}
+	for {
+		message, err := recv()
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+}
+
+func TestStreamingCalls(t *testing.T) {
+	a := assert.New(t)
+	client, stop := newTestClient(t)
+	defer stop()
+	ctx := context.Background()
+
+	groups, err := client.ListGroups(ctx, &ListGroupsRequest{})
+	a.NoError(err)
+	messages, err := receiveAll(func() (interface{}, error) { return groups.Recv() })
+	a.NoError(err)
+	a.Len(messages, 2)
+
+	groups, err = client.ListGroups(ctx, &ListGroupsRequest{MemberType: "GROUP"})
+	a.NoError(err)
+	messages, err = receiveAll(func() (interface{}, error) { return groups.Recv() })
+	a.NoError(err)
+	a.Len(messages, 1)
+
+	// The mock keeps no changes, so resuming requires a resync
+	watch, err := client.WatchChanges(ctx, &WatchChangesRequest{Since: 5})
+	a.NoError(err)
+	messages, err = receiveAll(func() (interface{}, error) { return watch.Recv() })
+	a.NoError(err)
+	if a.Len(messages, 1) {
+		event := messages[0].(*WatchEvent)
+		a.NotNil(event.Resync)
+		a.EqualValues(5, event.Resync.Since)
+	}
+}
@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetStatus(ctx context.Context, request *StatusRequest) (*Status, error) {
+	return toStatus(s.dirSync.Status()), nil
+}
+
+func (s *Server) GetGroup(ctx context.Context, request *GetGroupRequest) (*Group, error) {
+	if request.Group == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "group is required")
+	}
+
+	group, ok := s.dirSync.Snapshot().Group(request.Group)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "group %v not found", request.Group)
+	}
+	return toGroup(group), nil
+}
+
+func (s *Server) ListGroups(request *ListGroupsRequest, stream DirectoryService_ListGroupsServer) error {
+	snapshot := s.dirSync.Snapshot()
+	query := &directory.GroupQuery{
+		Query:        request.Query,
+		Prefix:       request.Prefix,
+		Domain:       request.Domain,
+		MemberRole:   request.MemberRole,
+		MemberStatus: request.MemberStatus,
+		MemberType:   request.MemberType,
+		// All groups fit on a single page
+		Limit: len(snapshot.Groups) + 1,
+	}
+	for _, group := range query.Search(snapshot.Groups).Groups {
+		if err := stream.Send(toGroup(group)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetMemberGroups(ctx context.Context, request *GetMemberGroupsRequest) (*GetMemberGroupsResponse, error) {
+	if request.Member == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "member is required")
+	}
+
+	snapshot := s.dirSync.Snapshot()
+	member, ok := snapshot.Member(request.Member)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "member %v not found", request.Member)
+	}
+
+	response := &GetMemberGroupsResponse{
+		Generation: snapshot.Generation,
+		Member:     toMember(member),
+	}
+	for _, group := range snapshot.MemberGroups(member.Id, request.Transitive) {
+		response.Groups = append(response.Groups, toMemberGroup(group))
+	}
+	return response, nil
+}
+
+func (s *Server) CheckMembership(ctx context.Context, request *CheckMembershipRequest) (*CheckMembershipResponse, error) {
+	if request.Member == "" || request.Group == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "member and group are required")
+	}
+
+	snapshot := s.dirSync.Snapshot()
+	result := snapshot.Check(request.Member, request.Group, sync.CheckOptions{
+		Transitive:       request.Transitive,
+		Roles:            request.Roles,
+		ExcludeSuspended: request.ExcludeSuspended,
+	})
+	if result.Error != "" {
+		return nil, status.Errorf(codes.NotFound, "%v", result.Error)
+	}
+	return &CheckMembershipResponse{
+		Generation: snapshot.Generation,
+		IsMember:   result.IsMember,
+		Depth:      int32(result.Depth),
+	}, nil
+}
+
+func (s *Server) WatchChanges(request *WatchChangesRequest, stream DirectoryService_WatchChangesServer) error {
+	filter := sync.NewChangeFilter(request.Groups, request.Members, s.dirSync.Snapshot().Emails)
+	watcher := sync.NewWatcher(s.dirSync, request.Since, request.Since > 0, filter)
+	defer watcher.Close()
+
+	for {
+		changes, snapshot, resync := watcher.Next()
+		if resync != nil {
+			return stream.Send(&WatchEvent{Resync: toResync(resync)})
+		}
+		for _, change := range changes {
+			if err := stream.Send(&WatchEvent{Change: toChange(change)}); err != nil {
+				return err
+			}
+		}
+		if snapshot != nil {
+			if err := stream.Send(&WatchEvent{Snapshot: toSnapshot(snapshot)}); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-watcher.Notifications():
+		case <-stream.Context().Done():
+			return status.Errorf(codes.Canceled, "%v", stream.Context().Err())
+		case <-s.shutdown:
+			return status.Errorf(codes.Unavailable, "server is shutting down")
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/sirupsen/logrus"
+)
+
+// Events show up in the activity feed with a delay. Every change sync therefore
+// looks back a bit before the checkpoint and skips the events it already saw.
+const changeLookback = 10 * time.Minute
+
+type checkpoint struct {
+	Time time.Time            `json:"time"`
+	Seen map[string]time.Time `json:"seen,omitempty"`
+}
+
+func (d *dirSync) isChangeSyncEnabled() bool {
+	return d.changeSync && d.googleClient != nil && d.googleClient.Reports != nil
+}
+
+// executeChangeSync reads the group events since the last checkpoint and
+// refreshes only the groups affected by them. Runs that refresh groups or fail
+// are recorded in the history.
+func (d *dirSync) executeChangeSync(ctx context.Context) {
+	current := d.Snapshot()
+	if !d.isChangeSyncEnabled() || current.Groups == nil || d.checkpoint.Time.IsZero() {
+		return
+	}
+
+	callsBefore := d.googleClient.Scheduler.Stats()
+	run := &SyncRun{Started: time.Now(), Kind: KindChange}
+	defer func() {
+		if run.Outcome == "" {
+			return
+		}
+		run.ApiCalls = d.googleClient.Scheduler.Stats().Sub(callsBefore).Calls
+		run.Finished = time.Now()
+		run.Duration = Duration{run.Finished.Sub(run.Started)}
+		d.history.add(run)
+	}()
+
+	changes, err := d.googleClient.Reports.RetrieveGroupChanges(ctx, d.checkpoint.Time.Add(-changeLookback))
+	if err != nil {
+		run.failed(ctx, err)
+		logrus.Errorf("Failed to retrieve group changes. Error: %v", err)
+		return
+	}
+
+	next := checkpoint{Time: d.checkpoint.Time, Seen: map[string]time.Time{}}
+	affected := map[string]struct{}{}
+	for _, change := range changes {
+		if _, ok := d.checkpoint.Seen[change.Key]; ok {
+			continue
+		}
+		next.Seen[change.Key] = change.Time
+		affected[strings.ToLower(change.GroupEmail)] = struct{}{}
+		if change.Time.After(next.Time) {
+			next.Time = change.Time
+		}
+	}
+
+	if len(affected) > 0 {
+		refresh, err := d.refreshGroups(ctx, current, affected)
+		if err != nil {
+			run.failed(ctx, err)
+			logrus.Errorf("Failed to refresh changed groups. Error: %v", err)
+			return
+		}
+
+		// The refreshed groups are applied to the latest snapshot, which may be
+		// newer than the one the events were resolved against. A quarantined
+		// result still consumes the events. The quarantine holds the refreshed
+		// groups and the next full sync fetches them again.
+		snapshot := d.publishGuardedWith(func(latest *Snapshot) Data {
+			return latest.withGroups(d.applyRefresh(latest, refresh))
+		})
+		if snapshot == nil {
+			run.Outcome = OutcomeQuarantined
+			run.Error = "synced directory was quarantined"
+		} else {
+			run.succeeded(snapshot)
+			d.applyRefreshResults(refresh)
+			err = d.persistToDisk(d.storageLocation, snapshot)
+			if err != nil {
+				logrus.Warnf("Failed to persist directory to disk: %v", err)
+			}
+		}
+		logrus.Infof("Refreshed %d groups after %d group events", len(affected), len(next.Seen))
+	}
+
+	for key, seen := range d.checkpoint.Seen {
+		if seen.After(next.Time.Add(-changeLookback)) {
+			next.Seen[key] = seen
+		}
+	}
+	d.updateCheckpoint(next)
+
+	d.updateStatus(func(status *Status) {
+		status.LastChangeSync = time.Now()
+		status.ChangedGroups = len(affected)
+	})
+}
+
+// groupRefresh holds the groups fetched again after group events.
+type groupRefresh struct {
+	fetchedAt time.Time
+	// fetched are the refreshed groups by id
+	fetched map[string]*directory.Group
+	// removed are the ids of known groups that no longer exist or were
+	// fetched again, possibly with a changed id
+	removed []string
+	// failed are the known groups that could not be fetched
+	failed map[string]*GroupFailure
+}
+
+// refreshGroups fetches the given groups again. Groups are resolved by their
+// id in the current snapshot where possible.
+func (d *dirSync) refreshGroups(ctx context.Context, current *Snapshot, emails map[string]struct{}) (*groupRefresh, error) {
+	refresh := &groupRefresh{
+		fetchedAt: time.Now(),
+		fetched:   map[string]*directory.Group{},
+		failed:    map[string]*GroupFailure{},
+	}
+
+	for email := range emails {
+		// Prefer the id as the email address might have been changed by the event
+		groupKey := email
+		member, known := current.Emails.Member(email)
+		known = known && member.Type == directory.GroupType
+		groupId := member.Id
+		if known {
+			groupKey = groupId
+		}
+
+		group, err := d.googleClient.Directory.RetrieveGroup(ctx, groupKey)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			logrus.Warnf("Failed to refresh group %s. Keeping the previous members. Error: %v", email, err)
+			if previous, ok := current.Groups[groupId]; known && ok {
+				refresh.failed[groupId] = &GroupFailure{GroupId: groupId, Email: previous.Email, Error: err.Error()}
+			}
+			continue
+		}
+
+		if known {
+			refresh.removed = append(refresh.removed, groupId)
+		}
+		if group != nil {
+			refresh.fetched[group.Id] = group
+		}
+	}
+
+	return refresh, nil
+}
+
+// applyRefresh returns a copy of the groups of the snapshot with the refresh
+// applied. Groups that failed keep their members from the snapshot and are
+// marked stale.
+func (d *dirSync) applyRefresh(snapshot *Snapshot, refresh *groupRefresh) map[string]*directory.Group {
+	groups := make(map[string]*directory.Group, len(snapshot.Groups))
+	for id, group := range snapshot.Groups {
+		groups[id] = group
+	}
+
+	for id := range refresh.failed {
+		previous, ok := groups[id]
+		if !ok {
+			continue
+		}
+		stale := *previous
+		d.markStale(&stale, previous)
+		groups[id] = &stale
+	}
+	for _, id := range refresh.removed {
+		delete(groups, id)
+	}
+	for id, group := range refresh.fetched {
+		groups[id] = group
+	}
+	return groups
+}
+
+// applyRefreshResults records the refreshed groups as fetched or failing once
+// they are published.
+func (d *dirSync) applyRefreshResults(refresh *groupRefresh) {
+	for _, failure := range refresh.failed {
+		d.applyGroupFailure(failure)
+	}
+	for id := range refresh.fetched {
+		d.applyGroupSuccess(id, refresh.fetchedAt)
+	}
+}
+
+// advanceCheckpoint moves the checkpoint to the start of a successful full
+// sync, as all events before it are contained in the synced directory.
+func (d *dirSync) advanceCheckpoint(syncStart time.Time) {
+	if !d.changeSync || !d.checkpoint.Time.Before(syncStart) {
+		return
+	}
+	d.updateCheckpoint(checkpoint{Time: syncStart, Seen: d.checkpoint.Seen})
+}
+
+func (d *dirSync) updateCheckpoint(checkpoint checkpoint) {
+	d.checkpoint = checkpoint
+	d.updateStatus(func(status *Status) {
+		status.ChangeCheckpoint = checkpoint.Time
+	})
+
+	err := d.persistCheckpoint(d.storageLocation)
+	if err != nil {
+		logrus.Warnf("Failed to persist change checkpoint to disk: %v", err)
+	}
+}
+
+func (d *dirSync) persistCheckpoint(location string) error {
+	if location == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(d.checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(location+"/checkpoint.json", data)
+}
+
+func (d *dirSync) restoreCheckpoint(location string) error {
+	if location == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(location + "/checkpoint.json")
+	if err != nil {
+		return err
+	}
+
+	var checkpoint checkpoint
+	err = json.Unmarshal(data, &checkpoint)
+	if err != nil {
+		return err
+	}
+
+	d.checkpoint = checkpoint
+	d.updateStatus(func(status *Status) {
+		status.ChangeCheckpoint = checkpoint.Time
+	})
+	return nil
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/fabzo/gcloud-directory-service/sync/google/googletest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/admin/directory/v1"
+)
+
+func TestQuarantinedChangeSyncConsumesEvents(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"})
+	client, err := google.NewWithHttpClient(server.Client(), "customer", "", google.Options{Reports: true})
+	a.NoError(err)
+
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	d := &dirSync{
+		googleClient: client,
+		changeSync:   true,
+		checkpoint:   checkpoint{Time: started},
+		thresholds:   Thresholds{MaxMembershipDrop: 50},
+		lastFetched:  map[string]time.Time{},
+		failures:     map[string]*GroupFailure{},
+		history:      newHistory(10),
+		changes:      newChangeLog(10),
+	}
+	group := &directory.Group{Id: "g1", Email: "g1@your.org", Members: map[string]*directory.Member{}}
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		group.Members[id] = &directory.Member{Id: id, Email: id + "@your.org", Type: directory.UserType}
+	}
+	d.publish(Data{Groups: map[string]*directory.Group{"g1": group}})
+
+	eventTime := started.Add(time.Minute)
+	server.AddGroupEvent("REMOVE_GROUP_MEMBER", "g1@your.org", eventTime)
+	d.executeChangeSync(context.Background())
+
+	a.NotNil(d.Quarantine())
+	a.EqualValues(1, d.Snapshot().Generation)
+	a.True(eventTime.Equal(d.checkpoint.Time))
+	runs := d.History().Runs
+	a.Len(runs, 1)
+	a.Equal(KindChange, runs[0].Kind)
+	a.Equal(OutcomeQuarantined, runs[0].Outcome)
+	a.EqualValues(3, runs[0].ApiCalls)
+
+	// The events are not refreshed again and runs without events are not
+	// recorded
+	d.executeChangeSync(context.Background())
+	a.Equal(1, server.Calls("/admin/directory/v1/groups/g1"))
+	a.Len(d.History().Runs, 1)
+
+	server.Fail("/admin/reports/v1/activity/users/all/applications/groups", 400)
+	d.executeChangeSync(context.Background())
+	runs = d.History().Runs
+	a.Len(runs, 2)
+	a.Equal(OutcomeFailed, runs[0].Outcome)
+	a.False(d.History().Health.Healthy)
+}
+
+func TestChangeSyncKeepsConcurrentPublish(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"},
+		&admin.Member{Id: "u2", Email: "u2@your.org", Type: "USER"})
+	client, err := google.NewWithHttpClient(server.Client(), "customer", "", google.Options{Reports: true})
+	a.NoError(err)
+
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	d := &dirSync{
+		googleClient: client,
+		changeSync:   true,
+		checkpoint:   checkpoint{Time: started},
+		lastFetched:  map[string]time.Time{},
+		failures:     map[string]*GroupFailure{},
+		history:      newHistory(10),
+		changes:      newChangeLog(10),
+	}
+	g1 := &directory.Group{Id: "g1", Email: "g1@your.org", Members: map[string]*directory.Member{
+		"u1": {Id: "u1", Email: "u1@your.org", Type: directory.UserType},
+	}}
+	g2 := &directory.Group{Id: "g2", Email: "g2@your.org"}
+	d.publish(Data{Groups: map[string]*directory.Group{"g1": g1}})
+
+	// Another snapshot is published while the changed group is fetched
+	server.Hook = func(r *http.Request) {
+		if r.URL.Path == "/admin/directory/v1/groups/g1" {
+			d.publish(Data{Groups: map[string]*directory.Group{"g1": g1, "g2": g2}})
+		}
+	}
+	server.AddGroupEvent("ADD_GROUP_MEMBER", "g1@your.org", started.Add(time.Minute))
+	d.executeChangeSync(context.Background())
+
+	snapshot := d.Snapshot()
+	a.EqualValues(3, snapshot.Generation)
+	a.Len(snapshot.Groups["g1"].Members, 2)
+	a.Equal(g2, snapshot.Groups["g2"])
+}
@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+// Change is a change of the directory that was published with the given
+// generation.
+type Change struct {
+	Generation uint64    `json:"generation"`
+	Time       time.Time `json:"time"`
+	*directory.GroupChange
+}
+
+// ChangeFeed lists the changes after the generation Since up to the current
+// generation, oldest first.
+type ChangeFeed struct {
+	Since      uint64    `json:"since"`
+	Generation uint64    `json:"generation"`
+	Changes    []*Change `json:"changes"`
+}
+
+// ResyncRequired is returned instead of a change feed if the requested
+// generation is not covered by the change log. The client has to retrieve the
+// whole directory again.
+type ResyncRequired struct {
+	ResyncRequired bool   `json:"resync_required"`
+	Since          uint64 `json:"since"`
+	// Oldest is the oldest generation changes can be requested for
+	Oldest     uint64 `json:"oldest_generation"`
+	Generation uint64 `json:"generation"`
+}
+
+type changeSet struct {
+	generation uint64
+	changes    []*Change
+}
+
+// changeLog keeps the changes of the last generations that changed anything
+// in a ring buffer.
+type changeLog struct {
+	mutex sync.Mutex
+	sets  []*changeSet
+	next  int
+	// oldest is the oldest generation the log has all later changes of
+	oldest uint64
+	// generation is the last generation added to the log
+	generation uint64
+}
+
+func newChangeLog(size int) *changeLog {
+	if size < 1 {
+		size = 1
+	}
+	return &changeLog{
+		sets: make([]*changeSet, 0, size),
+	}
+}
+
+// add records the changes from the previous to the next snapshot. The first
+// snapshot only starts the log, as there is nothing to compare it with.
+func (l *changeLog) add(previous *Snapshot, next *Snapshot) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.generation == 0 {
+		l.oldest = next.Generation
+		l.generation = next.Generation
+		return
+	}
+	l.generation = next.Generation
+
+	groupChanges := directory.DiffGroups(previous.Groups, next.Groups)
+	if len(groupChanges) == 0 {
+		return
+	}
+
+	set := &changeSet{generation: next.Generation}
+	for _, groupChange := range groupChanges {
+		set.changes = append(set.changes, &Change{
+			Generation:  next.Generation,
+			Time:        next.Created,
+			GroupChange: groupChange,
+		})
+	}
+
+	if len(l.sets) < cap(l.sets) {
+		l.sets = append(l.sets, set)
+	} else {
+		l.oldest = l.sets[l.next].generation
+		l.sets[l.next] = set
+	}
+	l.next = (l.next + 1) % cap(l.sets)
+}
+
+// since returns the changes after the given generation, or nil if the log
+// does not cover it.
+func (l *changeLog) since(generation uint64) (*ChangeFeed, *ResyncRequired) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.generation == 0 || generation < l.oldest || generation > l.generation {
+		return nil, &ResyncRequired{
+			ResyncRequired: true,
+			Since:          generation,
+			Oldest:         l.oldest,
+			Generation:     l.generation,
+		}
+	}
+
+	feed := &ChangeFeed{
+		Since:      generation,
+		Generation: l.generation,
+		Changes:    []*Change{},
+	}
+	for i := 0; i < len(l.sets); i++ {
+		set := l.sets[(l.next+i)%len(l.sets)]
+		if set.generation > generation {
+			feed.Changes = append(feed.Changes, set.changes...)
+		}
+	}
+	return feed, nil
+}
@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChangeLog(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(2)}
+	group := func(name string, aliases []string, members ...*directory.Member) map[string]*directory.Group {
+		group := &directory.Group{Id: "g1", Name: name, Email: "g1@your.org", Aliases: aliases, Members: map[string]*directory.Member{}}
+		for _, member := range members {
+			group.Members[member.Id] = member
+		}
+		return map[string]*directory.Group{"g1": group}
+	}
+	u1 := &directory.Member{Id: "u1", Email: "u1@your.org", Role: directory.MemberRole, Status: "ACTIVE"}
+	u1Owner := &directory.Member{Id: "u1", Email: "u1@your.org", Role: directory.OwnerRole, Status: "SUSPENDED"}
+	u2 := &directory.Member{Id: "u2", Email: "u2@your.org", Role: directory.MemberRole}
+
+	d.publish(Data{Groups: group("Team", nil, u1)})
+	_, resync := d.Changes(0)
+	a.NotNil(resync)
+	feed, resync := d.Changes(1)
+	a.Nil(resync)
+	a.Empty(feed.Changes)
+
+	d.publish(Data{Groups: group("Crew", []string{"crew@your.org"}, u1Owner, u2)})
+	feed, _ = d.Changes(1)
+	a.EqualValues(2, feed.Generation)
+	var types []string
+	for _, change := range feed.Changes {
+		a.EqualValues(2, change.Generation)
+		types = append(types, change.Type)
+	}
+	a.Equal([]string{
+		directory.GroupRenamed,
+		directory.AliasAdded,
+		directory.MemberRoleChanged,
+		directory.MemberStatusChanged,
+		directory.MemberAdded,
+	}, types)
+	a.Equal("Team", feed.Changes[0].Old)
+	a.Equal(directory.OwnerRole, feed.Changes[2].New)
+
+	d.publish(Data{Groups: group("Crew", []string{"crew@your.org"}, u1Owner, u2)})
+	d.publish(Data{Groups: map[string]*directory.Group{}})
+	d.publish(Data{Groups: group("Team", nil)})
+
+	// Generation 3 changed nothing, so the oldest change set of generation 2
+	// is dropped for generation 5
+	_, resync = d.Changes(1)
+	a.NotNil(resync)
+	a.EqualValues(2, resync.Oldest)
+	feed, resync = d.Changes(2)
+	a.Nil(resync)
+	a.Len(feed.Changes, 4)
+	a.Equal(directory.GroupRemoved, feed.Changes[0].Type)
+	feed, _ = d.Changes(4)
+	a.Len(feed.Changes, 1)
+	a.Equal(directory.GroupAdded, feed.Changes[0].Type)
+	_, resync = d.Changes(6)
+	a.NotNil(resync)
+}
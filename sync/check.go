@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"strings"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+// CheckOptions restrict which memberships satisfy a check.
+type CheckOptions struct {
+	// Transitive also accepts memberships through nested groups.
+	Transitive bool `json:"transitive"`
+	// Roles are the accepted roles in the checked group. Any role is accepted
+	// if empty. Roles of memberships in nested groups are not checked.
+	Roles []string `json:"roles,omitempty"`
+	// ExcludeSuspended ignores memberships through suspended members. Only
+	// users are suspended, so nested groups in between are not checked.
+	ExcludeSuspended bool `json:"exclude_suspended"`
+}
+
+type CheckResult struct {
+	Member   string `json:"member"`
+	Group    string `json:"group"`
+	IsMember bool   `json:"is_member"`
+	// Depth is the nesting depth of the shortest accepted membership
+	Depth int    `json:"depth,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Check reports whether the member is part of the group. Member and group can
+// be given by id, email address or alias. An unknown member is never part of
+// a group, an unknown group results in an error.
+func (s *Snapshot) Check(memberKey string, groupKey string, options CheckOptions) *CheckResult {
+	result := &CheckResult{Member: memberKey, Group: groupKey}
+
+	group, ok := s.Group(groupKey)
+	if !ok {
+		result.Error = "group " + groupKey + " not found"
+		return result
+	}
+	member, ok := s.Member(memberKey)
+	if !ok {
+		return result
+	}
+
+	starts := []string{member.Id}
+	if s.isCustomerUser(member) {
+		starts = append(starts, s.customerMemberIds...)
+	}
+
+	maxDepth := 1
+	if options.Transitive {
+		maxDepth = s.maxNestingDepth
+	}
+	for _, start := range starts {
+		depth := s.startDepth(start, group, maxDepth, options)
+		if depth > 0 && (result.Depth == 0 || depth < result.Depth) {
+			result.Depth = depth
+		}
+	}
+	result.IsMember = result.Depth > 0
+	return result
+}
+
+// startDepth returns the depth of the shortest accepted membership of the
+// start member in the target group or 0 if there is none.
+func (s *Snapshot) startDepth(start string, target *directory.Group, maxDepth int, options CheckOptions) int {
+	if !options.ExcludeSuspended {
+		return s.membershipDepth(start, target, 0, maxDepth, options)
+	}
+
+	if member, ok := target.Members[start]; ok && s.accepts(member, options) {
+		return 1
+	}
+	depth := 0
+	for _, groupId := range s.MemberIdToGroupIds[start] {
+		if s.isSuspended(s.Groups[groupId].Members[start]) {
+			continue
+		}
+		nested := s.membershipDepth(groupId, target, 1, maxDepth, options)
+		if nested > 0 && (depth == 0 || nested < depth) {
+			depth = nested
+		}
+	}
+	return depth
+}
+
+// membershipDepth looks up the memberships of the member in the transitive
+// closure and returns the depth of the first accepted membership in the
+// target group or 0 if there is none. The member itself is offset hops below
+// the member the depth is counted for.
+func (s *Snapshot) membershipDepth(memberId string, target *directory.Group, offset int, maxDepth int, options CheckOptions) int {
+	if maxDepth > 0 && offset >= maxDepth {
+		return 0
+	}
+	if member, ok := target.Members[memberId]; ok && s.accepts(member, options) {
+		return offset + 1
+	}
+	// Memberships are sorted by depth, so the first accepted one is the
+	// shortest
+	for _, membership := range s.Closure.Memberships[memberId] {
+		depth := offset + membership.Depth + 1
+		if maxDepth > 0 && depth > maxDepth {
+			break
+		}
+		if member, ok := target.Members[membership.GroupId]; ok && s.accepts(member, options) {
+			return depth
+		}
+	}
+	return 0
+}
+
+// accepts reports whether the membership in the checked group satisfies the
+// options.
+func (s *Snapshot) accepts(member *directory.Member, options CheckOptions) bool {
+	if options.ExcludeSuspended && s.isSuspended(member) {
+		return false
+	}
+	return hasRole(member.Role, options.Roles)
+}
+
+func hasRole(role string, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, accepted := range roles {
+		if strings.EqualFold(role, accepted) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckMembership(t *testing.T) {
+	a := assert.New(t)
+
+	groups := map[string]*directory.Group{
+		"g1": {Id: "g1", Email: "g1@your.org", Members: map[string]*directory.Member{
+			"u1": {Id: "u1", Email: "user@your.org", Type: directory.UserType, Role: "MEMBER"},
+			"u2": {Id: "u2", Email: "other@your.org", Type: directory.UserType, Role: "MEMBER", Status: "SUSPENDED"},
+		}},
+		"g2": {Id: "g2", Email: "g2@your.org", Members: map[string]*directory.Member{
+			"g1": {Id: "g1", Email: "g1@your.org", Type: directory.GroupType, Role: "OWNER"},
+		}},
+	}
+	snapshot := NewSnapshot(1, Data{Groups: groups}, 0)
+
+	result := snapshot.Check("user@your.org", "g1@your.org", CheckOptions{})
+	a.True(result.IsMember)
+	a.Equal(1, result.Depth)
+
+	a.False(snapshot.Check("user@your.org", "g2@your.org", CheckOptions{}).IsMember)
+	result = snapshot.Check("USER@your.org", "g2", CheckOptions{Transitive: true})
+	a.True(result.IsMember)
+	a.Equal(2, result.Depth)
+
+	a.True(snapshot.Check("u1", "g2", CheckOptions{Transitive: true, Roles: []string{"owner"}}).IsMember)
+	a.False(snapshot.Check("u1", "g1", CheckOptions{Roles: []string{"OWNER", "MANAGER"}}).IsMember)
+
+	a.True(snapshot.Check("u2", "g2", CheckOptions{Transitive: true}).IsMember)
+	a.False(snapshot.Check("u2", "g2", CheckOptions{Transitive: true, ExcludeSuspended: true}).IsMember)
+
+	a.False(snapshot.Check("unknown@your.org", "g1", CheckOptions{}).IsMember)
+	result = snapshot.Check("u1", "unknown@your.org", CheckOptions{})
+	a.False(result.IsMember)
+	a.NotEmpty(result.Error)
+}
+
+func TestCheckNestedMembership(t *testing.T) {
+	a := assert.New(t)
+
+	user := &directory.Member{Id: "u1", Email: "user@your.org", Type: directory.UserType, Role: "MEMBER"}
+	groups := map[string]*directory.Group{
+		"g1": {Id: "g1", Members: map[string]*directory.Member{"u1": {Id: "u1", Email: "user@your.org", Type: directory.UserType, Status: "SUSPENDED"}}},
+		"g2": {Id: "g2", Members: map[string]*directory.Member{"u1": user}},
+		"g3": {Id: "g3", Members: map[string]*directory.Member{"g2": {Id: "g2", Type: directory.GroupType}}},
+		"g4": {Id: "g4", Members: map[string]*directory.Member{
+			"g1": {Id: "g1", Type: directory.GroupType, Role: "OWNER"},
+			"g3": {Id: "g3", Type: directory.GroupType, Role: "MEMBER"},
+		}},
+		"g5": {Id: "g5", Members: map[string]*directory.Member{"c1": {Id: "c1", Type: CustomerType}}},
+		"g6": {Id: "g6", Members: map[string]*directory.Member{"g5": {Id: "g5", Type: directory.GroupType}}},
+	}
+	domains := map[string]*directory.Domain{"your.org": {Name: "your.org", IsPrimary: true}}
+	snapshot := NewSnapshot(1, Data{Groups: groups, Domains: domains}, 0)
+	transitive := CheckOptions{Transitive: true}
+
+	// The shortest path leads through the suspended membership in g1
+	a.Equal(2, snapshot.Check("u1", "g4", transitive).Depth)
+	excluded := snapshot.Check("u1", "g4", CheckOptions{Transitive: true, ExcludeSuspended: true})
+	a.True(excluded.IsMember)
+	a.Equal(3, excluded.Depth)
+	a.False(snapshot.Check("u1", "g4", CheckOptions{Transitive: true, ExcludeSuspended: true, Roles: []string{"OWNER"}}).IsMember)
+
+	// Users of the customer are members through CUSTOMER members
+	result := snapshot.Check("user@your.org", "g6", transitive)
+	a.True(result.IsMember)
+	a.Equal(2, result.Depth)
+	a.False(snapshot.Check("g1", "g6", transitive).IsMember)
+
+	limited := NewSnapshot(1, Data{Groups: groups, Domains: domains}, 2)
+	a.Equal(2, limited.Check("u1", "g4", transitive).Depth)
+	a.False(limited.Check("u1", "g4", CheckOptions{Transitive: true, ExcludeSuspended: true}).IsMember)
+}
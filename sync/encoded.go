@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+)
+
+// EncodedJson is a JSON response body that is encoded once and served to
+// every request. Gzip is the gzip compressed body, if it was compressed in
+// advance.
+type EncodedJson struct {
+	Json []byte
+	Gzip []byte
+}
+
+// EncodedResponses are the bodies of the bulk endpoints for a snapshot. They
+// are encoded when the snapshot is published, as they are the same for every
+// request.
+type EncodedResponses struct {
+	Directory *EncodedJson
+	Groups    *EncodedJson
+	Members   *EncodedJson
+	Users     *EncodedJson
+}
+
+// EncodeJson encodes the value as JSON and compresses it with gzip. A nil map
+// is encoded as an empty object.
+func EncodeJson(value interface{}) (*EncodedJson, error) {
+	encoded := []byte("{}")
+	if v := reflect.ValueOf(value); v.Kind() != reflect.Map || !v.IsNil() {
+		var err error
+		encoded, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(encoded)
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncodedJson{Json: encoded, Gzip: compressed.Bytes()}, nil
+}
+
+func encodeResponses(s *Snapshot) *EncodedResponses {
+	return &EncodedResponses{
+		Directory: mustEncodeJson(s.Groups),
+		Groups:    mustEncodeJson(s.EmailToMember),
+		Members:   mustEncodeJson(s.MemberIdToGroupIds),
+		Users:     mustEncodeJson(s.Users),
+	}
+}
+
+// mustEncodeJson encodes values that consist of plain data only and can
+// therefore always be encoded.
+func mustEncodeJson(value interface{}) *EncodedJson {
+	encoded, err := EncodeJson(value)
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
@@ -0,0 +1,214 @@
+package sync
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+const (
+	// CustomerType is the member type that stands for all users of the
+	// customer.
+	CustomerType = "CUSTOMER"
+
+	suspendedStatus = "SUSPENDED"
+
+	// maxExplainedPaths limits the number of paths returned for a single
+	// explanation, as heavily nested groups can have exponentially many.
+	maxExplainedPaths = 100
+)
+
+// Hop is a single membership on the path from a member to a group.
+type Hop struct {
+	GroupId     string `json:"group_id"`
+	GroupEmail  string `json:"group_email,omitempty"`
+	MemberId    string `json:"member_id"`
+	MemberEmail string `json:"member_email,omitempty"`
+	MemberType  string `json:"member_type,omitempty"`
+	Role        string `json:"role,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Suspended   bool   `json:"suspended"`
+}
+
+// MembershipPath is a chain of memberships that makes a member part of a
+// group. The first hop starts at the member, the last one ends at the group.
+type MembershipPath struct {
+	Hops []*Hop `json:"hops"`
+	// Suspended is set if any member on the path is suspended.
+	Suspended bool `json:"suspended"`
+	// Customer is set if the path starts with a CUSTOMER member, meaning the
+	// membership is granted to all users of the customer.
+	Customer bool `json:"customer"`
+}
+
+// Explanation lists all paths through which a member is part of a group.
+type Explanation struct {
+	Member    *directory.Member `json:"member"`
+	GroupId   string            `json:"group_id"`
+	IsMember  bool              `json:"is_member"`
+	Suspended bool              `json:"suspended"`
+	Customer  bool              `json:"customer"`
+	Paths     []*MembershipPath `json:"paths"`
+	// Truncated is set if there are more paths than returned.
+	Truncated bool `json:"truncated"`
+}
+
+// Explain returns every path from the member to the group through nested
+// groups, up to the nesting depth of the snapshot. Users of the customer are
+// additionally considered through CUSTOMER members, which requires either the
+// users or the domains to be synced.
+func (s *Snapshot) Explain(member *directory.Member, groupId string) *Explanation {
+	explanation := &Explanation{
+		Member:  member,
+		GroupId: groupId,
+		Paths:   []*MembershipPath{},
+	}
+
+	explainer := &explainer{
+		snapshot:    s,
+		target:      groupId,
+		visited:     map[string]bool{member.Id: true},
+		explanation: explanation,
+	}
+	explainer.walk(member.Id, nil)
+	if s.isCustomerUser(member) {
+		for _, customerId := range s.customerMemberIds {
+			explainer.walk(customerId, nil)
+		}
+	}
+
+	explanation.IsMember = len(explanation.Paths) > 0
+	for _, path := range explanation.Paths {
+		explanation.Suspended = explanation.Suspended || path.Suspended
+		explanation.Customer = explanation.Customer || path.Customer
+	}
+	return explanation
+}
+
+type explainer struct {
+	snapshot    *Snapshot
+	target      string
+	visited     map[string]bool
+	explanation *Explanation
+}
+
+// walk follows the memberships of the member depth first. Groups already on
+// the current path are skipped, so cycles end the path, and so are groups that
+// cannot reach the target within the remaining depth.
+func (e *explainer) walk(memberId string, hops []*Hop) {
+	maxDepth := e.snapshot.maxNestingDepth
+	if maxDepth > 0 && len(hops) >= maxDepth {
+		return
+	}
+
+	groupIds := append([]string{}, e.snapshot.MemberIdToGroupIds[memberId]...)
+	sort.Strings(groupIds)
+	for _, groupId := range groupIds {
+		if e.visited[groupId] {
+			continue
+		}
+		if len(e.explanation.Paths) >= maxExplainedPaths {
+			e.explanation.Truncated = true
+			return
+		}
+
+		path := append(hops[:len(hops):len(hops)], e.snapshot.hop(groupId, memberId))
+		if groupId == e.target {
+			e.explanation.Paths = append(e.explanation.Paths, toMembershipPath(path))
+			continue
+		}
+
+		if !e.reaches(groupId, len(path)) {
+			continue
+		}
+		e.visited[groupId] = true
+		e.walk(groupId, path)
+		e.visited[groupId] = false
+	}
+}
+
+// reaches reports whether the target is a nested group of the group that is
+// reachable on a path that already has the given number of hops.
+func (e *explainer) reaches(groupId string, hops int) bool {
+	maxDepth := e.snapshot.maxNestingDepth
+	for _, membership := range e.snapshot.Closure.Memberships[groupId] {
+		if maxDepth > 0 && hops+membership.Depth > maxDepth {
+			return false
+		}
+		if membership.GroupId == e.target {
+			return true
+		}
+	}
+	return false
+}
+
+func toMembershipPath(hops []*Hop) *MembershipPath {
+	path := &MembershipPath{
+		Hops:     hops,
+		Customer: hops[0].MemberType == CustomerType,
+	}
+	for _, hop := range hops {
+		path.Suspended = path.Suspended || hop.Suspended
+	}
+	return path
+}
+
+func (s *Snapshot) hop(groupId string, memberId string) *Hop {
+	group := s.Groups[groupId]
+	member := group.Members[memberId]
+	hop := &Hop{
+		GroupId:     groupId,
+		GroupEmail:  group.Email,
+		MemberId:    memberId,
+		MemberEmail: member.Email,
+		MemberType:  member.Type,
+		Role:        member.Role,
+		Status:      member.Status,
+		Suspended:   s.isSuspended(member),
+	}
+	return hop
+}
+
+// isSuspended reports whether the member is suspended in the group or is a
+// suspended user.
+func (s *Snapshot) isSuspended(member *directory.Member) bool {
+	if member.Status == suspendedStatus {
+		return true
+	}
+	user, ok := s.Users[member.Id]
+	return ok && user.Suspended
+}
+
+// isCustomerUser reports whether the member is a user of the customer, either
+// because it is a synced user or because its email address is in a domain of
+// the customer.
+func (s *Snapshot) isCustomerUser(member *directory.Member) bool {
+	if member.Type != directory.UserType {
+		return false
+	}
+	if _, ok := s.Users[member.Id]; ok {
+		return true
+	}
+	canonical := s.Emails.Canonical(member.Email)
+	_, ok := s.Domains[canonical[strings.LastIndex(canonical, "@")+1:]]
+	return ok
+}
+
+// toCustomerMemberIds returns the sorted ids of all CUSTOMER members.
+func toCustomerMemberIds(groups map[string]*directory.Group) []string {
+	ids := map[string]struct{}{}
+	for _, group := range groups {
+		for id, member := range group.Members {
+			if member.Type == CustomerType {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	result := make([]string, 0, len(ids))
+	for id := range ids {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"fmt"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExplainMembership(t *testing.T) {
+	a := assert.New(t)
+
+	groups := map[string]*directory.Group{
+		"g1": {Id: "g1", Email: "g1@your.org", Members: map[string]*directory.Member{
+			"u1": {Id: "u1", Email: "user@your.org", Type: directory.UserType, Role: "OWNER", Status: "ACTIVE"},
+		}},
+		"g2": {Id: "g2", Email: "g2@your.org", Members: map[string]*directory.Member{
+			"u1": {Id: "u1", Email: "user@your.org", Type: directory.UserType, Role: "MEMBER", Status: "SUSPENDED"},
+			"g1": {Id: "g1", Email: "g1@your.org", Type: directory.GroupType, Role: "MEMBER"},
+		}},
+		"g3": {Id: "g3", Email: "g3@your.org", Members: map[string]*directory.Member{
+			"g2": {Id: "g2", Email: "g2@your.org", Type: directory.GroupType, Role: "MANAGER"},
+			"c1": {Id: "c1", Type: CustomerType, Role: "MEMBER"},
+		}},
+	}
+	domains := map[string]*directory.Domain{"your.org": {Name: "your.org", IsPrimary: true}}
+	snapshot := NewSnapshot(1, Data{Groups: groups, Domains: domains}, 0)
+
+	member, ok := snapshot.Member("USER@your.org")
+	a.True(ok)
+	explanation := snapshot.Explain(member, "g3")
+	a.True(explanation.IsMember)
+	a.True(explanation.Suspended)
+	a.True(explanation.Customer)
+	a.Len(explanation.Paths, 3)
+
+	a.Len(explanation.Paths[0].Hops, 3)
+	a.Equal("OWNER", explanation.Paths[0].Hops[0].Role)
+	a.Equal("MANAGER", explanation.Paths[0].Hops[2].Role)
+	a.False(explanation.Paths[0].Suspended)
+	a.True(explanation.Paths[1].Suspended)
+	a.True(explanation.Paths[2].Customer)
+
+	external, ok := snapshot.Member("g1@your.org")
+	a.True(ok)
+	explanation = snapshot.Explain(external, "g3")
+	a.Len(explanation.Paths, 1)
+	a.False(explanation.Customer)
+
+	limited := NewSnapshot(1, Data{Groups: groups}, 1)
+	a.False(limited.Explain(member, "g3").IsMember)
+}
+
+func TestExplainSkipsGroupsNotReachingTarget(t *testing.T) {
+	a := assert.New(t)
+
+	// Every group of a layer is a member of every group of the next layer, so
+	// the user reaches the top group on 4^16 paths, none of them through the
+	// target
+	const width, layers = 4, 16
+	user := &directory.Member{Id: "u1", Email: "user@your.org", Type: directory.UserType}
+	groups := map[string]*directory.Group{
+		"target": {Id: "target", Members: map[string]*directory.Member{"u1": user}},
+	}
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			id := fmt.Sprintf("l%d-%d", layer, i)
+			members := map[string]*directory.Member{}
+			if layer == 0 {
+				members["u1"] = user
+			}
+			for j := 0; layer > 0 && j < width; j++ {
+				memberId := fmt.Sprintf("l%d-%d", layer-1, j)
+				members[memberId] = &directory.Member{Id: memberId, Type: directory.GroupType}
+			}
+			groups[id] = &directory.Group{Id: id, Members: members}
+		}
+	}
+
+	snapshot := NewSnapshot(1, Data{Groups: groups}, 0)
+	explanation := snapshot.Explain(user, "target")
+	a.True(explanation.IsMember)
+	a.False(explanation.Truncated)
+	a.Len(explanation.Paths, 1)
+	a.Len(explanation.Paths[0].Hops, 1)
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupFailure struct {
+	GroupId     string    `json:"group_id"`
+	Email       string    `json:"email"`
+	Error       string    `json:"error"`
+	LastSuccess time.Time `json:"last_success"`
+}
+
+// markFailed marks the groups whose members could not be retrieved as stale
+// and takes over their members and settings from the current snapshot.
+func (d *dirSync) markFailed(current *Snapshot, groups map[string]*directory.Group, failed map[string]error) {
+	for id, group := range groups {
+		err, hasFailed := failed[id]
+		if !hasFailed {
+			continue
+		}
+
+		d.markStale(group, current.Groups[id])
+		logrus.Warnf("Failed to retrieve members of group %s (%s). Keeping the previous members. Error: %v", group.Email, id, err)
+	}
+}
+
+// applyFailures records the groups whose members could not be retrieved as
+// failing. All other groups are recorded as successfully fetched at the given
+// time. It is only called once the groups are published, a quarantined sync
+// leaves the recorded state of the published snapshot untouched.
+func (d *dirSync) applyFailures(groups map[string]*directory.Group, failed map[string]error, fetchedAt time.Time) {
+	lastFetched := make(map[string]time.Time, len(groups))
+	failures := map[string]*GroupFailure{}
+
+	for id, group := range groups {
+		err, hasFailed := failed[id]
+		if !hasFailed {
+			lastFetched[id] = fetchedAt
+			continue
+		}
+
+		if last, ok := d.lastFetched[id]; ok {
+			lastFetched[id] = last
+		}
+		failures[id] = &GroupFailure{
+			GroupId:     id,
+			Email:       group.Email,
+			Error:       err.Error(),
+			LastSuccess: lastFetched[id],
+		}
+	}
+
+	d.lastFetched = lastFetched
+	d.failures = failures
+	d.updateFailureStatus()
+}
+
+// applyGroupFailure records the failed refresh of a single group.
+func (d *dirSync) applyGroupFailure(failure *GroupFailure) {
+	failure.LastSuccess = d.lastFetched[failure.GroupId]
+	d.failures[failure.GroupId] = failure
+	d.updateFailureStatus()
+}
+
+// applyGroupSuccess records the successful refresh of a single group.
+func (d *dirSync) applyGroupSuccess(groupId string, fetchedAt time.Time) {
+	d.lastFetched[groupId] = fetchedAt
+	if _, ok := d.failures[groupId]; ok {
+		delete(d.failures, groupId)
+		d.updateFailureStatus()
+	}
+}
+
+func (d *dirSync) markStale(group *directory.Group, previous *directory.Group) {
+	group.Stale = true
+	if previous != nil {
+		group.Members = previous.Members
+		group.Settings = previous.Settings
+	}
+}
+
+func (d *dirSync) updateFailureStatus() {
+	failures := make([]*GroupFailure, 0, len(d.failures))
+	for _, failure := range d.failures {
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].GroupId < failures[j].GroupId
+	})
+	d.updateStatus(func(status *Status) {
+		status.FailingGroups = failures
+	})
+}
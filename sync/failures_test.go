@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google"
+	"github.com/fabzo/gcloud-directory-service/sync/google/googletest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/admin/directory/v1"
+)
+
+func TestFailedGroupsKeepPreviousMembers(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"})
+	server.SetGroup(&admin.Group{Id: "g2", Email: "g2@your.org"},
+		&admin.Member{Id: "u2", Email: "u2@your.org", Type: "USER"})
+	client, err := google.NewWithHttpClient(server.Client(), "customer", "", google.Options{})
+	a.NoError(err)
+	d := &dirSync{
+		googleClient: client,
+		syncInterval: 60,
+		lastFetched:  map[string]time.Time{},
+		failures:     map[string]*GroupFailure{},
+		history:      newHistory(10),
+		changes:      newChangeLog(10),
+	}
+
+	d.executeSync(context.Background())
+	a.Len(d.Snapshot().Groups["g2"].Members, 1)
+	fetched := d.lastFetched["g2"]
+
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"})
+	server.Fail("/admin/directory/v1/groups/g2/members", 400)
+	d.executeSync(context.Background())
+
+	snapshot := d.Snapshot()
+	a.EqualValues(2, snapshot.Generation)
+	a.False(snapshot.Groups["g1"].Stale)
+	a.Empty(snapshot.Groups["g1"].Members)
+	a.True(snapshot.Groups["g2"].Stale)
+	a.Contains(snapshot.Groups["g2"].Members, "u2")
+	failing := d.Status().FailingGroups
+	a.Len(failing, 1)
+	a.Equal("g2", failing[0].GroupId)
+	a.Equal(fetched, failing[0].LastSuccess)
+	a.Equal(OutcomeSuccess, d.History().Runs[0].Outcome)
+
+	server.Fail("/admin/directory/v1/groups/g2/members", 0)
+	d.executeSync(context.Background())
+	a.False(d.Snapshot().Groups["g2"].Stale)
+	a.Empty(d.Status().FailingGroups)
+	a.True(d.lastFetched["g2"].After(fetched))
+}
+
+func TestQuarantinedSyncKeepsFailures(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"},
+		&admin.Member{Id: "u2", Email: "u2@your.org", Type: "USER"})
+	server.SetGroup(&admin.Group{Id: "g2", Email: "g2@your.org"},
+		&admin.Member{Id: "u3", Email: "u3@your.org", Type: "USER"})
+	client, err := google.NewWithHttpClient(server.Client(), "customer", "", google.Options{})
+	a.NoError(err)
+	d := &dirSync{
+		googleClient: client,
+		syncInterval: 60,
+		thresholds:   Thresholds{MaxMembershipDrop: 50},
+		lastFetched:  map[string]time.Time{},
+		failures:     map[string]*GroupFailure{},
+		history:      newHistory(10),
+		changes:      newChangeLog(10),
+	}
+
+	d.executeSync(context.Background())
+	fetched := d.lastFetched["g1"]
+
+	// The failure of g2 is not recorded for the quarantined directory
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"})
+	server.Fail("/admin/directory/v1/groups/g2/members", 400)
+	d.executeSync(context.Background())
+	a.NotNil(d.Quarantine())
+	a.True(d.Quarantine().Data.Groups["g2"].Stale)
+	a.Empty(d.Status().FailingGroups)
+	a.Equal(fetched, d.lastFetched["g1"])
+	a.Equal(fetched, d.lastFetched["g2"])
+}
@@ -6,16 +6,73 @@ import (
 	"net/http"
 
 	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/fabzo/gcloud-directory-service/sync/google/quota"
+	"github.com/fabzo/gcloud-directory-service/sync/google/reports"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2/jwt"
 	"google.golang.org/api/admin/directory/v1"
+	auditreports "google.golang.org/api/admin/reports/v1"
+	"google.golang.org/api/groupssettings/v1"
 )
 
 type Client struct {
 	Directory *directory.Service
+	Reports   *reports.Service
+	Scheduler *quota.Scheduler
 }
 
-func New(credentials []byte, subject string, customerId string, domain string) (*Client, error) {
+type Options struct {
+	// Workers is the number of groups whose members are retrieved in parallel.
+	Workers int
+	// QPS is the number of API calls per second shared by all services.
+	QPS float64
+	// MaxRetries is the number of retries for calls with retryable errors.
+	MaxRetries int
+	// Reports enables the client for the admin reports API. This requires the
+	// audit readonly scope to be granted to the service account.
+	Reports bool
+	// Users enables the retrieval of users. This requires the user readonly
+	// scope to be granted to the service account.
+	Users bool
+	// OrgUnits enables the retrieval of org units. This requires the orgunit
+	// readonly scope to be granted to the service account.
+	OrgUnits bool
+	// GroupSettings enables the retrieval of group settings. This requires the
+	// groups settings scope to be granted to the service account.
+	GroupSettings bool
+	// AdminRoles enables the retrieval of admin roles and their assignments.
+	// This requires the role management readonly scope to be granted to the
+	// service account.
+	AdminRoles bool
+	// Domains enables the retrieval of domains and domain aliases. This
+	// requires the domain readonly scope to be granted to the service account.
+	Domains bool
+}
+
+func (o Options) scopes() []string {
+	scopes := []string{admin.AdminDirectoryGroupReadonlyScope, admin.AdminDirectoryGroupMemberReadonlyScope}
+	if o.Users {
+		scopes = append(scopes, admin.AdminDirectoryUserReadonlyScope)
+	}
+	if o.OrgUnits {
+		scopes = append(scopes, admin.AdminDirectoryOrgunitReadonlyScope)
+	}
+	if o.Domains {
+		scopes = append(scopes, admin.AdminDirectoryDomainReadonlyScope)
+	}
+	if o.AdminRoles {
+		scopes = append(scopes, admin.AdminDirectoryRolemanagementReadonlyScope)
+	}
+	if o.GroupSettings {
+		scopes = append(scopes, groupssettings.AppsGroupsSettingsScope)
+	}
+	if o.Reports {
+		scopes = append(scopes, auditreports.AdminReportsAuditReadonlyScope)
+	}
+	return scopes
+}
+
+func New(credentials []byte, subject string, customerId string, domain string, options Options) (*Client, error) {
 	logrus.Debug("Creating new google client")
 	var credentialsMap map[string]string
 	err := json.Unmarshal(credentials, &credentialsMap)
@@ -28,7 +85,7 @@ func New(credentials []byte, subject string, customerId string, domain string) (
 		Email:        string(credentialsMap["client_email"]),
 		PrivateKey:   []byte(credentialsMap["private_key"]),
 		PrivateKeyID: string(credentialsMap["private_key_id"]),
-		Scopes:       []string{admin.AdminDirectoryGroupReadonlyScope, admin.AdminDirectoryGroupMemberReadonlyScope},
+		Scopes:       options.scopes(),
 		TokenURL:     string(credentialsMap["token_uri"]),
 		Subject:      subject,
 	}
@@ -37,16 +94,28 @@ func New(credentials []byte, subject string, customerId string, domain string) (
 
 	httpClient := config.Client(context.Background())
 
-	return NewWithHttpClient(httpClient, customerId, domain)
+	return NewWithHttpClient(httpClient, customerId, domain, options)
 }
 
-func NewWithHttpClient(httpClient *http.Client, customerId string, domain string) (*Client, error) {
-	directoryService, err := directory.New(httpClient, customerId, domain)
+func NewWithHttpClient(httpClient *http.Client, customerId string, domain string, options Options) (*Client, error) {
+	scheduler := quota.New(options.QPS, options.MaxRetries)
+
+	directoryService, err := directory.New(httpClient, customerId, domain, options.Workers, options.GroupSettings, scheduler)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
+	client := &Client{
 		Directory: directoryService,
-	}, nil
+		Scheduler: scheduler,
+	}
+
+	if options.Reports {
+		client.Reports, err = reports.New(httpClient, scheduler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
 }
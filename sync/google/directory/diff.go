@@ -0,0 +1,141 @@
+package directory
+
+import (
+	"sort"
+)
+
+const (
+	GroupAdded          = "group_added"
+	GroupRemoved        = "group_removed"
+	GroupRenamed        = "group_renamed"
+	GroupEmailChanged   = "group_email_changed"
+	AliasAdded          = "alias_added"
+	AliasRemoved        = "alias_removed"
+	MemberAdded         = "member_added"
+	MemberRemoved       = "member_removed"
+	MemberRoleChanged   = "member_role_changed"
+	MemberStatusChanged = "member_status_changed"
+)
+
+// ChangeTypes lists all types of group changes.
+var ChangeTypes = []string{
+	GroupAdded, GroupRemoved, GroupRenamed, GroupEmailChanged, AliasAdded, AliasRemoved,
+	MemberAdded, MemberRemoved, MemberRoleChanged, MemberStatusChanged,
+}
+
+// GroupChange is a single difference of a group between two directories. Old
+// and New hold the changed value for renames, email, alias, role and status
+// changes.
+type GroupChange struct {
+	Type        string `json:"type"`
+	GroupId     string `json:"group_id"`
+	GroupEmail  string `json:"group_email,omitempty"`
+	MemberId    string `json:"member_id,omitempty"`
+	MemberEmail string `json:"member_email,omitempty"`
+	MemberType  string `json:"member_type,omitempty"`
+	Old         string `json:"old,omitempty"`
+	New         string `json:"new,omitempty"`
+}
+
+// DiffGroups returns the changes from the previous to the next groups, sorted
+// by group id. Added and removed groups come with an added or removed change
+// for each of their members.
+func DiffGroups(previous map[string]*Group, next map[string]*Group) []*GroupChange {
+	changes := make([]*GroupChange, 0)
+
+	for id, group := range previous {
+		if _, ok := next[id]; !ok {
+			changes = append(changes, &GroupChange{Type: GroupRemoved, GroupId: id, GroupEmail: group.Email})
+			changes = append(changes, diffMembers(group, group.Members, nil)...)
+		}
+	}
+
+	for id, group := range next {
+		old, ok := previous[id]
+		if !ok {
+			changes = append(changes, &GroupChange{Type: GroupAdded, GroupId: id, GroupEmail: group.Email})
+			changes = append(changes, diffMembers(group, nil, group.Members)...)
+			continue
+		}
+
+		if old.Name != group.Name {
+			changes = append(changes, &GroupChange{Type: GroupRenamed, GroupId: id, GroupEmail: group.Email, Old: old.Name, New: group.Name})
+		}
+		if old.Email != group.Email {
+			changes = append(changes, &GroupChange{Type: GroupEmailChanged, GroupId: id, GroupEmail: group.Email, Old: old.Email, New: group.Email})
+		}
+		changes = append(changes, diffAliases(group, old.Aliases, group.Aliases)...)
+		changes = append(changes, diffMembers(group, old.Members, group.Members)...)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].GroupId != changes[j].GroupId {
+			return changes[i].GroupId < changes[j].GroupId
+		}
+		return changes[i].MemberId < changes[j].MemberId
+	})
+	return changes
+}
+
+func diffAliases(group *Group, previous []string, next []string) []*GroupChange {
+	var changes []*GroupChange
+	for _, alias := range missing(previous, next) {
+		changes = append(changes, &GroupChange{Type: AliasRemoved, GroupId: group.Id, GroupEmail: group.Email, Old: alias})
+	}
+	for _, alias := range missing(next, previous) {
+		changes = append(changes, &GroupChange{Type: AliasAdded, GroupId: group.Id, GroupEmail: group.Email, New: alias})
+	}
+	return changes
+}
+
+// missing returns the sorted values of a that are not part of b.
+func missing(a []string, b []string) []string {
+	known := map[string]bool{}
+	for _, value := range b {
+		known[value] = true
+	}
+	var result []string
+	for _, value := range a {
+		if !known[value] {
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func diffMembers(group *Group, previous map[string]*Member, next map[string]*Member) []*GroupChange {
+	var changes []*GroupChange
+	change := func(changeType string, member *Member, old string, new string) {
+		changes = append(changes, &GroupChange{
+			Type:        changeType,
+			GroupId:     group.Id,
+			GroupEmail:  group.Email,
+			MemberId:    member.Id,
+			MemberEmail: member.Email,
+			MemberType:  member.Type,
+			Old:         old,
+			New:         new,
+		})
+	}
+
+	for id, member := range previous {
+		if _, ok := next[id]; !ok {
+			change(MemberRemoved, member, "", "")
+		}
+	}
+	for id, member := range next {
+		old, ok := previous[id]
+		if !ok {
+			change(MemberAdded, member, "", "")
+			continue
+		}
+		if old.Role != member.Role {
+			change(MemberRoleChanged, member, old.Role, member.Role)
+		}
+		if old.Status != member.Status {
+			change(MemberStatusChanged, member, old.Status, member.Status)
+		}
+	}
+	return changes
+}
@@ -1,9 +1,13 @@
 package directory
 
 import (
+	"context"
 	"net/http"
+	"sync"
 
+	"github.com/fabzo/gcloud-directory-service/sync/google/quota"
 	"google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/groupssettings/v1"
 )
 
 const (
@@ -11,39 +15,186 @@ const (
 )
 
 type Service struct {
+	httpClient       *http.Client
 	directoryService *admin.Service
+	settingsService  *groupssettings.Service
 	customerId       string
 	domain           string
+	workers          int
+	scheduler        *quota.Scheduler
 }
 
-func New(client *http.Client, customerId string, domain string) (*Service, error) {
+// New creates a directory service. With groupSettings the settings of every
+// retrieved group are fetched alongside its members.
+func New(client *http.Client, customerId string, domain string, workers int, groupSettings bool, scheduler *quota.Scheduler) (*Service, error) {
 	service, err := admin.New(client)
 	if err != nil {
 		return nil, err
 	}
 
+	var settingsService *groupssettings.Service
+	if groupSettings {
+		settingsService, err = groupssettings.New(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Service{
+		httpClient:       client,
 		directoryService: service,
+		settingsService:  settingsService,
 		customerId:       customerId,
 		domain:           domain,
+		workers:          workers,
+		scheduler:        scheduler,
 	}, nil
 }
 
-func (c *Service) RetrieveDirectory() (map[string]*Group, error) {
-	groups, err := c.retrieveGroups()
+func (c *Service) Workers() int {
+	return c.workers
+}
+
+type Stats struct {
+	Reused    int
+	Refetched int
+	// Failed contains the groups whose members could not be retrieved. These
+	// groups are part of the result, but without members.
+	Failed map[string]error
+	// Deleted contains the groups that disappeared while the sync was running.
+	// They are not part of the result.
+	Deleted []string
+}
+
+// RetrieveDirectory retrieves all groups and their members. If a previous
+// directory is given, the members of groups whose ETag did not change are
+// taken over from it and only the changed groups are fetched again. Stale
+// groups of the previous directory are always fetched again. Changing the
+// settings of a group does not change its ETag, so the settings of all groups
+// are fetched on every sync.
+//
+// Failing to retrieve the members of a single group does not fail the whole
+// directory, the error is reported in the stats instead.
+func (c *Service) RetrieveDirectory(ctx context.Context, previous map[string]*Group) (map[string]*Group, *Stats, error) {
+	groups, err := c.retrieveGroups(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	for _, group := range groups {
-		members, err := c.retrieveMembers(group.Id)
+	stats := &Stats{}
+	jobs := make([]*contentJob, 0, len(groups))
+	for id, group := range groups {
+		if old, ok := previous[id]; ok && !old.Stale && old.ETag != "" && old.ETag == group.ETag {
+			group.Members = old.Members
+			stats.Reused++
+			if c.settingsService != nil {
+				jobs = append(jobs, &contentJob{group: group, settingsOnly: true})
+			}
+			continue
+		}
+		jobs = append(jobs, &contentJob{group: group})
+	}
+	stats.Refetched = len(groups) - stats.Reused
+
+	stats.Failed, err = c.retrieveAllContent(ctx, jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for id, err := range stats.Failed {
+		if isNotFound(err) {
+			delete(groups, id)
+			delete(stats.Failed, id)
+			stats.Deleted = append(stats.Deleted, id)
+		}
+	}
+
+	return groups, stats, nil
+}
+
+// contentJob retrieves the content of a single group. With settingsOnly the
+// members of the group are kept.
+type contentJob struct {
+	group        *Group
+	settingsOnly bool
+}
+
+// retrieveAllContent fetches the content of all given groups using a pool of
+// workers. Every group is handled by exactly one worker, so the content can be
+// assigned without further locking. Errors of single groups are collected and
+// returned per group id, only a done context stops the remaining work.
+func (c *Service) retrieveAllContent(ctx context.Context, contentJobs []*contentJob) (map[string]error, error) {
+	workers := c.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(contentJobs) {
+		workers = len(contentJobs)
+	}
+
+	jobs := make(chan *contentJob)
+
+	var wg sync.WaitGroup
+	var failedMutex sync.Mutex
+	failed := map[string]error{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				err := c.retrieveContent(ctx, job.group, !job.settingsOnly)
+				if err != nil {
+					failedMutex.Lock()
+					failed[job.group.Id] = err
+					failedMutex.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range contentJobs {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return failed, nil
+}
+
+// retrieveContent fetches the members, unless the members of the group are
+// kept, and, if enabled, the settings of the group. The group is only changed
+// if everything could be retrieved.
+func (c *Service) retrieveContent(ctx context.Context, group *Group, withMembers bool) error {
+	members := group.Members
+	if withMembers {
+		var err error
+		members, err = c.retrieveMembers(ctx, group.Id)
 		if err != nil {
-			return nil, err
+			return err
+		}
+	}
+
+	var settings *GroupSettings
+	if c.settingsService != nil {
+		var err error
+		settings, err = c.retrieveSettings(ctx, group.Email)
+		if err != nil {
+			return err
 		}
-		group.Members = members
 	}
 
-	return groups, nil
+	group.Members = members
+	group.Settings = settings
+	return nil
 }
 
 func ToMemberIdGroupIdsMapping(groups map[string]*Group) map[string][]string {
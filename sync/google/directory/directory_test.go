@@ -0,0 +1,143 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/googletest"
+	"github.com/fabzo/gcloud-directory-service/sync/google/quota"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/groupssettings/v1"
+)
+
+func newTestService(t *testing.T, server *googletest.Server, workers int, groupSettings bool) *Service {
+	service, err := New(server.Client(), "customer", "", workers, groupSettings, quota.New(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestWorkersBoundConcurrentRetrieval(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	for i := 0; i < 12; i++ {
+		id := fmt.Sprintf("g%d", i)
+		server.SetGroup(&admin.Group{Id: id, Email: id + "@your.org"},
+			&admin.Member{Id: "u" + id, Email: "u" + id + "@your.org", Type: "USER"})
+	}
+	server.Fail("/admin/directory/v1/groups/g3/members", 400)
+
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server.Hook = func(r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/members") {
+			return
+		}
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+	}
+	service := newTestService(t, server, 3, false)
+
+	groups, stats, err := service.RetrieveDirectory(context.Background(), nil)
+	a.NoError(err)
+	a.Len(groups, 12)
+	a.Equal(12, stats.Refetched)
+	a.True(maxInFlight > 1, "members are retrieved in parallel")
+	a.True(maxInFlight <= 3, "at most 3 workers, got %d", maxInFlight)
+
+	// A failing group is reported without members, all others are complete
+	a.Len(stats.Failed, 1)
+	a.Contains(stats.Failed, "g3")
+	a.Empty(groups["g3"].Members)
+	a.Len(groups["g4"].Members, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = service.RetrieveDirectory(ctx, nil)
+	a.Error(err)
+}
+
+func TestUnchangedGroupsReused(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org", Etag: "a1"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"})
+	server.SetGroup(&admin.Group{Id: "g2", Email: "g2@your.org", Etag: "b1"},
+		&admin.Member{Id: "u2", Email: "u2@your.org", Type: "USER"})
+	server.SetGroup(&admin.Group{Id: "g3", Email: "g3@your.org", Etag: "c1"})
+	service := newTestService(t, server, 2, false)
+
+	previous, stats, err := service.RetrieveDirectory(context.Background(), nil)
+	a.NoError(err)
+	a.Equal(0, stats.Reused)
+	a.Equal(3, stats.Refetched)
+
+	// g2 changed, g3 is stale and g4 is new
+	server.SetGroup(&admin.Group{Id: "g2", Email: "g2@your.org", Etag: "b2"},
+		&admin.Member{Id: "u3", Email: "u3@your.org", Type: "USER"})
+	server.SetGroup(&admin.Group{Id: "g4", Email: "g4@your.org", Etag: "d1"})
+	previous["g3"].Stale = true
+
+	groups, stats, err := service.RetrieveDirectory(context.Background(), previous)
+	a.NoError(err)
+	a.Equal(1, stats.Reused)
+	a.Equal(3, stats.Refetched)
+	a.Equal(1, server.Calls("/admin/directory/v1/groups/g1/members"))
+	a.Equal(2, server.Calls("/admin/directory/v1/groups/g2/members"))
+	a.Equal(2, server.Calls("/admin/directory/v1/groups/g3/members"))
+	a.Equal(previous["g1"].Members, groups["g1"].Members)
+	a.Contains(groups["g2"].Members, "u3")
+	a.NotContains(groups["g2"].Members, "u2")
+	a.False(groups["g3"].Stale)
+	a.Len(groups, 4)
+}
+
+func TestSettingsRefreshedForUnchangedGroups(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org", Etag: "e1"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"})
+	server.SetSettings("g1@your.org", &groupssettings.Groups{WhoCanJoin: "INVITED_CAN_JOIN"})
+	service := newTestService(t, server, 2, true)
+
+	previous, _, err := service.RetrieveDirectory(context.Background(), nil)
+	a.NoError(err)
+	a.Equal("INVITED_CAN_JOIN", previous["g1"].Settings.WhoCanJoin)
+
+	server.SetSettings("g1@your.org", &groupssettings.Groups{WhoCanJoin: "ANYONE_CAN_JOIN"})
+	groups, stats, err := service.RetrieveDirectory(context.Background(), previous)
+	a.NoError(err)
+	a.Equal(1, stats.Reused)
+	a.Equal(1, server.Calls("/admin/directory/v1/groups/g1/members"))
+	a.Equal(previous["g1"].Members, groups["g1"].Members)
+	a.Equal("ANYONE_CAN_JOIN", groups["g1"].Settings.WhoCanJoin)
+
+	// Failing settings fail the group like failing members
+	server.Fail("/groups/v1/groups/g1@your.org", 403)
+	_, stats, err = service.RetrieveDirectory(context.Background(), groups)
+	a.NoError(err)
+	a.Contains(stats.Failed, "g1")
+}
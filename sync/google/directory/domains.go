@@ -0,0 +1,80 @@
+package directory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/admin/directory/v1"
+)
+
+type Domain struct {
+	Name      string         `json:"name,omitempty"`
+	IsPrimary bool           `json:"is_primary"`
+	Verified  bool           `json:"verified"`
+	Aliases   []*DomainAlias `json:"aliases,omitempty"`
+	ETag      string         `json:"etag,omitempty"`
+}
+
+type DomainAlias struct {
+	Name     string `json:"name,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// RetrieveDomains returns all domains of the customer including their domain
+// aliases by their lower case name.
+func (c *Service) RetrieveDomains(ctx context.Context) (map[string]*Domain, error) {
+	listCall := c.directoryService.Domains.List(c.customerId).Context(ctx)
+
+	var domains *admin.Domains2
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		domains, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*Domain{}
+	for _, k := range domains.Domains {
+		domain := toDomain(k)
+		result[domain.Name] = domain
+	}
+	return result, nil
+}
+
+func toDomain(domain *admin.Domains) *Domain {
+	result := &Domain{
+		Name:      strings.ToLower(domain.DomainName),
+		IsPrimary: domain.IsPrimary,
+		Verified:  domain.Verified,
+		ETag:      domain.Etag,
+	}
+	for _, k := range domain.DomainAliases {
+		result.Aliases = append(result.Aliases, &DomainAlias{
+			Name:     strings.ToLower(k.DomainAliasName),
+			Verified: k.Verified,
+		})
+	}
+	sort.Slice(result.Aliases, func(i, j int) bool {
+		return result.Aliases[i].Name < result.Aliases[j].Name
+	})
+	return result
+}
+
+// ToDomainList returns the domains with the primary domain first and all
+// others sorted by name.
+func ToDomainList(domains map[string]*Domain) []*Domain {
+	result := make([]*Domain, 0, len(domains))
+	for _, domain := range domains {
+		result = append(result, domain)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].IsPrimary != result[j].IsPrimary {
+			return result[i].IsPrimary
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,97 @@
+package directory
+
+import (
+	"strings"
+)
+
+// Canonicalizer maps the different spellings of an email address to a single
+// canonical one. Google treats email addresses case insensitive and delivers
+// mails to an alias domain to the same account in the parent domain.
+type Canonicalizer struct {
+	aliasDomains map[string]string
+}
+
+func NewCanonicalizer(domains map[string]*Domain) *Canonicalizer {
+	aliasDomains := map[string]string{}
+	for name, domain := range domains {
+		for _, alias := range domain.Aliases {
+			aliasDomains[alias.Name] = name
+		}
+	}
+	return &Canonicalizer{aliasDomains: aliasDomains}
+}
+
+// Canonical returns the lower case email address in which an alias domain is
+// replaced by its parent domain.
+func (c *Canonicalizer) Canonical(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	if parent, ok := c.aliasDomains[email[at+1:]]; ok {
+		return email[:at+1] + parent
+	}
+	return email
+}
+
+// EmailIndex resolves email addresses of groups, users and members in their
+// canonical form. Group and user aliases resolve to the group or user they
+// belong to.
+type EmailIndex struct {
+	canonicalizer *Canonicalizer
+	members       map[string]MemberType
+	users         map[string]string
+}
+
+func NewEmailIndex(groups map[string]*Group, users map[string]*User, domains map[string]*Domain) *EmailIndex {
+	index := &EmailIndex{
+		canonicalizer: NewCanonicalizer(domains),
+		members:       map[string]MemberType{},
+		users:         map[string]string{},
+	}
+
+	// Members are added first, so the groups and users themselves take
+	// precedence over the member entries referring to them.
+	for _, group := range groups {
+		for _, member := range group.Members {
+			index.addMember(member.Email, MemberType{Id: member.Id, Type: member.Type})
+		}
+	}
+	for id, user := range users {
+		for _, email := range user.Emails() {
+			index.addMember(email, MemberType{Id: id, Type: UserType})
+			index.users[index.Canonical(email)] = id
+		}
+	}
+	for id, group := range groups {
+		for _, email := range group.Emails() {
+			index.addMember(email, MemberType{Id: id, Type: GroupType})
+		}
+	}
+	return index
+}
+
+func (i *EmailIndex) addMember(email string, memberType MemberType) {
+	if email == "" {
+		return
+	}
+	i.members[i.Canonical(email)] = memberType
+}
+
+// Canonical returns the canonical form of the email address.
+func (i *EmailIndex) Canonical(email string) string {
+	return i.canonicalizer.Canonical(email)
+}
+
+// Member returns the group, user or other member known by the email address.
+func (i *EmailIndex) Member(email string) (MemberType, bool) {
+	member, ok := i.members[i.Canonical(email)]
+	return member, ok
+}
+
+// UserId returns the id of the user known by the email address.
+func (i *EmailIndex) UserId(email string) (string, bool) {
+	id, ok := i.users[i.Canonical(email)]
+	return id, ok
+}
@@ -0,0 +1,47 @@
+package directory
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEmailCanonicalization(t *testing.T) {
+	a := assert.New(t)
+
+	index := NewEmailIndex(
+		map[string]*Group{
+			"g1": {Id: "g1", Email: "Team@your.org", Aliases: []string{"crew@your.org"}, NonEditableAliases: []string{"team@alias-your.org"}, Members: map[string]*Member{
+				"m1": {Id: "m1", Email: "external@other.org", Type: UserType},
+			}},
+		},
+		map[string]*User{
+			"u1": {Id: "u1", PrimaryEmail: "Jane.Doe@your.org", Aliases: []string{"jane@your.org"}},
+		},
+		map[string]*Domain{
+			"your.org": {Name: "your.org", IsPrimary: true, Aliases: []*DomainAlias{{Name: "alias-your.org"}}},
+		},
+	)
+
+	a.Equal("jane.doe@your.org", index.Canonical(" JANE.DOE@Alias-Your.org"))
+	a.Equal("no-domain", index.Canonical("No-Domain"))
+
+	id, ok := index.UserId("jane.doe@alias-your.org")
+	a.True(ok)
+	a.Equal("u1", id)
+	id, ok = index.UserId("JANE@your.org")
+	a.True(ok)
+	a.Equal("u1", id)
+
+	member, ok := index.Member("CREW@alias-your.org")
+	a.True(ok)
+	a.Equal(MemberType{Id: "g1", Type: GroupType}, member)
+	member, ok = index.Member("TEAM@alias-your.org")
+	a.True(ok)
+	a.Equal("g1", member.Id)
+	member, ok = index.Member("External@Other.org")
+	a.True(ok)
+	a.Equal("m1", member.Id)
+
+	_, ok = index.Member("jane.doe@other.org")
+	a.False(ok)
+}
@@ -1,18 +1,38 @@
 package directory
 
-import "google.golang.org/api/admin/directory/v1"
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
 
 type Group struct {
-	Id          string             `json:"id,omitempty"`
-	Name        string             `json:"name,omitempty"`
-	Description string             `json:"description,omitempty"`
-	Email       string             `json:"email,omitempty"`
-	ETag        string             `json:"etag,omitempty"`
-	Aliases     []string           `json:"aliases,omitempty"`
-	Members     map[string]*Member `json:"members,omitempty"`
+	Id                 string             `json:"id,omitempty"`
+	Name               string             `json:"name,omitempty"`
+	Description        string             `json:"description,omitempty"`
+	Email              string             `json:"email,omitempty"`
+	ETag               string             `json:"etag,omitempty"`
+	Aliases            []string           `json:"aliases,omitempty"`
+	NonEditableAliases []string           `json:"non_editable_aliases,omitempty"`
+	Members            map[string]*Member `json:"members,omitempty"`
+	Settings           *GroupSettings     `json:"settings,omitempty"`
+	// Stale is set if the members could not be retrieved during the last sync
+	// and are taken over from an earlier one.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// Emails returns the email address and all aliases of the group.
+func (g *Group) Emails() []string {
+	emails := make([]string, 0, 1+len(g.Aliases)+len(g.NonEditableAliases))
+	emails = append(emails, g.Email)
+	emails = append(emails, g.Aliases...)
+	emails = append(emails, g.NonEditableAliases...)
+	return emails
 }
 
-func (c *Service) retrieveGroups() (map[string]*Group, error) {
+func (c *Service) retrieveGroups(ctx context.Context) (map[string]*Group, error) {
 	completeGroups := map[string]*Group{}
 	nextPageToken := ""
 
@@ -20,7 +40,7 @@ func (c *Service) retrieveGroups() (map[string]*Group, error) {
 	var err error
 
 	for {
-		groups, nextPageToken, err = c.groupCall(nextPageToken)
+		groups, nextPageToken, err = c.groupCall(ctx, nextPageToken)
 		if err != nil {
 			return nil, err
 		}
@@ -36,19 +56,54 @@ func (c *Service) retrieveGroups() (map[string]*Group, error) {
 	return completeGroups, nil
 }
 
+// RetrieveGroup fetches a single group including its members and settings. The group key
+// can be the id, the email address or an alias of the group. A group that does
+// not exist (anymore) is returned as nil without an error.
+func (c *Service) RetrieveGroup(ctx context.Context, groupKey string) (*Group, error) {
+	var group *admin.Group
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		group, err = c.directoryService.Groups.Get(groupKey).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := toGroup(group)
+	err = c.retrieveContent(ctx, result, true)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == http.StatusNotFound
+}
+
 func toGroup(group *admin.Group) *Group {
 	return &Group{
-		Id:          group.Id,
-		Name:        group.Name,
-		Description: group.Description,
-		Email:       group.Email,
-		ETag:        group.Etag,
-		Aliases:     group.Aliases,
+		Id:                 group.Id,
+		Name:               group.Name,
+		Description:        group.Description,
+		Email:              group.Email,
+		ETag:               group.Etag,
+		Aliases:            group.Aliases,
+		NonEditableAliases: group.NonEditableAliases,
 	}
 }
 
-func (c *Service) groupCall(pageToken string) (*admin.Groups, string, error) {
-	listCall := c.directoryService.Groups.List().Customer(c.customerId).MaxResults(10000)
+func (c *Service) groupCall(ctx context.Context, pageToken string) (*admin.Groups, string, error) {
+	listCall := c.directoryService.Groups.List().Customer(c.customerId).MaxResults(10000).Context(ctx)
 	if pageToken != "" {
 		listCall = listCall.PageToken(pageToken)
 	}
@@ -56,7 +111,12 @@ func (c *Service) groupCall(pageToken string) (*admin.Groups, string, error) {
 		listCall = listCall.Domain(c.domain)
 	}
 
-	groups, err := listCall.Do()
+	var groups *admin.Groups
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		groups, err = listCall.Do()
+		return err
+	})
 	if err != nil {
 		return nil, "", err
 	}
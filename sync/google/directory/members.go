@@ -1,6 +1,16 @@
 package directory
 
-import "google.golang.org/api/admin/directory/v1"
+import (
+	"context"
+
+	"google.golang.org/api/admin/directory/v1"
+)
+
+const (
+	OwnerRole   = "OWNER"
+	ManagerRole = "MANAGER"
+	MemberRole  = "MEMBER"
+)
 
 type Member struct {
 	Id     string `json:"id,omitempty"`
@@ -9,6 +19,8 @@ type Member struct {
 	Role   string `json:"role,omitempty"`
 	Status string `json:"status,omitempty"`
 	Type   string `json:"type,omitempty"`
+	// Name is only set on request and never part of the synced directory
+	Name string `json:"name,omitempty"`
 }
 
 type MemberType struct {
@@ -16,7 +28,7 @@ type MemberType struct {
 	Type string `json:"type,omitempty"`
 }
 
-func (c *Service) retrieveMembers(groupId string) (map[string]*Member, error) {
+func (c *Service) retrieveMembers(ctx context.Context, groupId string) (map[string]*Member, error) {
 	completeMembers := map[string]*Member{}
 	nextPageToken := ""
 
@@ -24,7 +36,7 @@ func (c *Service) retrieveMembers(groupId string) (map[string]*Member, error) {
 	var err error
 
 	for {
-		members, nextPageToken, err = c.memberCall(groupId, nextPageToken)
+		members, nextPageToken, err = c.memberCall(ctx, groupId, nextPageToken)
 		if err != nil {
 			return nil, err
 		}
@@ -51,13 +63,18 @@ func toMember(member *admin.Member) *Member {
 	}
 }
 
-func (c *Service) memberCall(groupId string, pageToken string) (*admin.Members, string, error) {
-	listCall := c.directoryService.Members.List(groupId).MaxResults(10000)
+func (c *Service) memberCall(ctx context.Context, groupId string, pageToken string) (*admin.Members, string, error) {
+	listCall := c.directoryService.Members.List(groupId).MaxResults(10000).Context(ctx)
 	if pageToken != "" {
 		listCall = listCall.PageToken(pageToken)
 	}
 
-	members, err := listCall.Do()
+	var members *admin.Members
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		members, err = listCall.Do()
+		return err
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -0,0 +1,103 @@
+package directory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/admin/directory/v1"
+)
+
+const RootOrgUnitPath = "/"
+
+type OrgUnit struct {
+	Id               string `json:"id,omitempty"`
+	Name             string `json:"name,omitempty"`
+	Description      string `json:"description,omitempty"`
+	Path             string `json:"path,omitempty"`
+	ParentId         string `json:"parent_id,omitempty"`
+	ParentPath       string `json:"parent_path,omitempty"`
+	BlockInheritance bool   `json:"block_inheritance,omitempty"`
+	ETag             string `json:"etag,omitempty"`
+}
+
+// OrgUnitNode is an org unit together with its sub org units.
+type OrgUnitNode struct {
+	*OrgUnit
+	Children []*OrgUnitNode `json:"children,omitempty"`
+}
+
+// RetrieveOrgUnits returns all org units of the customer by their path. The
+// root org unit is not part of the result.
+func (c *Service) RetrieveOrgUnits(ctx context.Context) (map[string]*OrgUnit, error) {
+	listCall := c.directoryService.Orgunits.List(c.customerId).Type("all").Context(ctx)
+
+	var orgUnits *admin.OrgUnits
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		orgUnits, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*OrgUnit{}
+	for _, k := range orgUnits.OrganizationUnits {
+		result[k.OrgUnitPath] = toOrgUnit(k)
+	}
+	return result, nil
+}
+
+func toOrgUnit(orgUnit *admin.OrgUnit) *OrgUnit {
+	return &OrgUnit{
+		Id:               orgUnit.OrgUnitId,
+		Name:             orgUnit.Name,
+		Description:      orgUnit.Description,
+		Path:             orgUnit.OrgUnitPath,
+		ParentId:         orgUnit.ParentOrgUnitId,
+		ParentPath:       orgUnit.ParentOrgUnitPath,
+		BlockInheritance: orgUnit.BlockInheritance,
+		ETag:             orgUnit.Etag,
+	}
+}
+
+// ToOrgUnitTree arranges the org units below a root node. Org units whose
+// parent is unknown are attached to the root. Children are sorted by path.
+func ToOrgUnitTree(orgUnits map[string]*OrgUnit) *OrgUnitNode {
+	root := &OrgUnitNode{OrgUnit: &OrgUnit{Name: "", Path: RootOrgUnitPath}}
+	nodes := map[string]*OrgUnitNode{RootOrgUnitPath: root}
+	for path, orgUnit := range orgUnits {
+		nodes[path] = &OrgUnitNode{OrgUnit: orgUnit}
+	}
+
+	for path, orgUnit := range orgUnits {
+		parent, ok := nodes[orgUnit.ParentPath]
+		if !ok || orgUnit.ParentPath == path {
+			parent = root
+		}
+		parent.Children = append(parent.Children, nodes[path])
+	}
+
+	for _, node := range nodes {
+		sort.Slice(node.Children, func(i, j int) bool {
+			return node.Children[i].Path < node.Children[j].Path
+		})
+	}
+	return root
+}
+
+// IsInOrgUnit reports whether the org unit path equals the given path or, if
+// recursive, lies below it.
+func IsInOrgUnit(orgUnitPath string, path string, recursive bool) bool {
+	if orgUnitPath == path {
+		return true
+	}
+	if !recursive {
+		return false
+	}
+	if path == RootOrgUnitPath {
+		return true
+	}
+	return strings.HasPrefix(orgUnitPath, path+"/")
+}
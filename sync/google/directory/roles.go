@@ -0,0 +1,274 @@
+package directory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/admin/directory/v1"
+)
+
+const (
+	CustomerScope = "CUSTOMER"
+	OrgUnitScope  = "ORG_UNIT"
+)
+
+type AdminRole struct {
+	Id               string           `json:"id,omitempty"`
+	Name             string           `json:"name,omitempty"`
+	Description      string           `json:"description,omitempty"`
+	IsSystemRole     bool             `json:"is_system_role"`
+	IsSuperAdminRole bool             `json:"is_super_admin_role"`
+	Privileges       []*RolePrivilege `json:"privileges,omitempty"`
+	ETag             string           `json:"etag,omitempty"`
+}
+
+type RolePrivilege struct {
+	Name        string `json:"name,omitempty"`
+	ServiceId   string `json:"service_id,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	// OrgUnitScopable is set if the privilege can be restricted to an org unit
+	OrgUnitScopable bool `json:"org_unit_scopable"`
+}
+
+type RoleAssignment struct {
+	Id string `json:"id,omitempty"`
+	// RoleId is the id of the assigned AdminRole
+	RoleId string `json:"role_id,omitempty"`
+	// AssignedTo is the id of the user holding the role
+	AssignedTo string `json:"assigned_to,omitempty"`
+	// ScopeType is either CUSTOMER or ORG_UNIT
+	ScopeType string `json:"scope_type,omitempty"`
+	// OrgUnitId is the org unit the role is restricted to for the ORG_UNIT scope
+	OrgUnitId string `json:"org_unit_id,omitempty"`
+	ETag      string `json:"etag,omitempty"`
+}
+
+// AdminRoles are the admin roles of the customer and their assignments, both
+// by id.
+type AdminRoles struct {
+	Roles       map[string]*AdminRole      `json:"roles"`
+	Assignments map[string]*RoleAssignment `json:"assignments"`
+}
+
+// RetrieveAdminRoles retrieves all admin roles and their assignments. The
+// privileges of every role are completed with the service name from the
+// privileges of the customer.
+func (c *Service) RetrieveAdminRoles(ctx context.Context) (*AdminRoles, error) {
+	privileges, err := c.retrievePrivileges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := c.retrieveRoles(ctx, privileges)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := c.retrieveRoleAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdminRoles{
+		Roles:       roles,
+		Assignments: assignments,
+	}, nil
+}
+
+// retrievePrivileges returns all privileges including the child privileges by
+// their service id and name.
+func (c *Service) retrievePrivileges(ctx context.Context) (map[string]*admin.Privilege, error) {
+	listCall := c.directoryService.Privileges.List(c.customerId).Context(ctx)
+
+	var privileges *admin.Privileges
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		privileges, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*admin.Privilege{}
+	var add func(privileges []*admin.Privilege)
+	add = func(privileges []*admin.Privilege) {
+		for _, k := range privileges {
+			result[privilegeKey(k.ServiceId, k.PrivilegeName)] = k
+			add(k.ChildPrivileges)
+		}
+	}
+	add(privileges.Items)
+	return result, nil
+}
+
+func privilegeKey(serviceId string, name string) string {
+	return serviceId + "/" + name
+}
+
+func (c *Service) retrieveRoles(ctx context.Context, privileges map[string]*admin.Privilege) (map[string]*AdminRole, error) {
+	completeRoles := map[string]*AdminRole{}
+	nextPageToken := ""
+
+	var roles *admin.Roles
+	var err error
+
+	for {
+		roles, nextPageToken, err = c.roleCall(ctx, nextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range roles.Items {
+			role := toAdminRole(k, privileges)
+			completeRoles[role.Id] = role
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+	}
+
+	return completeRoles, nil
+}
+
+func toAdminRole(role *admin.Role, privileges map[string]*admin.Privilege) *AdminRole {
+	result := &AdminRole{
+		Id:               strconv.FormatInt(role.RoleId, 10),
+		Name:             role.RoleName,
+		Description:      role.RoleDescription,
+		IsSystemRole:     role.IsSystemRole,
+		IsSuperAdminRole: role.IsSuperAdminRole,
+		ETag:             role.Etag,
+	}
+	for _, k := range role.RolePrivileges {
+		privilege := &RolePrivilege{
+			Name:      k.PrivilegeName,
+			ServiceId: k.ServiceId,
+		}
+		if known, ok := privileges[privilegeKey(k.ServiceId, k.PrivilegeName)]; ok {
+			privilege.ServiceName = known.ServiceName
+			privilege.OrgUnitScopable = known.IsOuScopable
+		}
+		result.Privileges = append(result.Privileges, privilege)
+	}
+	return result
+}
+
+func (c *Service) roleCall(ctx context.Context, pageToken string) (*admin.Roles, string, error) {
+	listCall := c.directoryService.Roles.List(c.customerId).MaxResults(100).Context(ctx)
+	if pageToken != "" {
+		listCall = listCall.PageToken(pageToken)
+	}
+
+	var roles *admin.Roles
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		roles, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return roles, roles.NextPageToken, nil
+}
+
+func (c *Service) retrieveRoleAssignments(ctx context.Context) (map[string]*RoleAssignment, error) {
+	completeAssignments := map[string]*RoleAssignment{}
+	nextPageToken := ""
+
+	var assignments *admin.RoleAssignments
+	var err error
+
+	for {
+		assignments, nextPageToken, err = c.roleAssignmentCall(ctx, nextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range assignments.Items {
+			assignment := toRoleAssignment(k)
+			completeAssignments[assignment.Id] = assignment
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+	}
+
+	return completeAssignments, nil
+}
+
+func toRoleAssignment(assignment *admin.RoleAssignment) *RoleAssignment {
+	return &RoleAssignment{
+		Id:         strconv.FormatInt(assignment.RoleAssignmentId, 10),
+		RoleId:     strconv.FormatInt(assignment.RoleId, 10),
+		AssignedTo: assignment.AssignedTo,
+		ScopeType:  assignment.ScopeType,
+		OrgUnitId:  assignment.OrgUnitId,
+		ETag:       assignment.Etag,
+	}
+}
+
+func (c *Service) roleAssignmentCall(ctx context.Context, pageToken string) (*admin.RoleAssignments, string, error) {
+	listCall := c.directoryService.RoleAssignments.List(c.customerId).MaxResults(200).Context(ctx)
+	if pageToken != "" {
+		listCall = listCall.PageToken(pageToken)
+	}
+
+	var assignments *admin.RoleAssignments
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		assignments, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return assignments, assignments.NextPageToken, nil
+}
+
+// UserAdminRole is a role assignment of a user together with the assigned
+// role and, for org unit scoped assignments, the path of the org unit.
+type UserAdminRole struct {
+	*RoleAssignment
+	OrgUnitPath string     `json:"org_unit_path,omitempty"`
+	Role        *AdminRole `json:"role,omitempty"`
+}
+
+// ToUserAdminRoles returns the role assignments of the user sorted by role
+// and assignment id. Org unit ids are resolved against the given org units
+// if possible.
+func (a *AdminRoles) ToUserAdminRoles(userId string, orgUnits map[string]*OrgUnit) []*UserAdminRole {
+	result := make([]*UserAdminRole, 0)
+	if a == nil {
+		return result
+	}
+
+	orgUnitPaths := map[string]string{}
+	for path, orgUnit := range orgUnits {
+		orgUnitPaths[strings.TrimPrefix(orgUnit.Id, "id:")] = path
+	}
+	for _, assignment := range a.Assignments {
+		if assignment.AssignedTo != userId {
+			continue
+		}
+		userRole := &UserAdminRole{
+			RoleAssignment: assignment,
+			Role:           a.Roles[assignment.RoleId],
+		}
+		if assignment.ScopeType == OrgUnitScope {
+			userRole.OrgUnitPath = orgUnitPaths[strings.TrimPrefix(assignment.OrgUnitId, "id:")]
+		}
+		result = append(result, userRole)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RoleId != result[j].RoleId {
+			return result[i].RoleId < result[j].RoleId
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
@@ -0,0 +1,214 @@
+package directory
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// groupFields are the fields of a group that can be selected by a query.
+var groupFields = map[string]func(target *Group, source *Group){
+	"id":          func(target *Group, source *Group) { target.Id = source.Id },
+	"name":        func(target *Group, source *Group) { target.Name = source.Name },
+	"description": func(target *Group, source *Group) { target.Description = source.Description },
+	"email":       func(target *Group, source *Group) { target.Email = source.Email },
+	"etag":        func(target *Group, source *Group) { target.ETag = source.ETag },
+	"aliases":     func(target *Group, source *Group) { target.Aliases = source.Aliases },
+	"members":     func(target *Group, source *Group) { target.Members = source.Members },
+	"settings":    func(target *Group, source *Group) { target.Settings = source.Settings },
+	"stale":       func(target *Group, source *Group) { target.Stale = source.Stale },
+}
+
+// GroupQuery selects, orders and pages groups. Text matches are case
+// insensitive. Member filters restrict the members of every group and exclude
+// groups without matching members.
+type GroupQuery struct {
+	// Query matches groups whose name, email or aliases contain it
+	Query string
+	// Prefix matches groups whose name, email or aliases start with it
+	Prefix string
+	// Domain matches groups whose email is in the domain
+	Domain       string
+	MemberRole   string
+	MemberStatus string
+	MemberType   string
+	Settings     SettingsFilter
+	// Fields are the fields of the returned groups, all if empty
+	Fields []string
+	// Cursor continues a previous query after its last group
+	Cursor string
+	Limit  int
+}
+
+// GroupPage is a page of groups ordered by email and id. NextCursor is empty
+// on the last page.
+type GroupPage struct {
+	Groups     []*Group `json:"groups"`
+	Total      int      `json:"total"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// ParseGroupQuery reads a group query from the parameters. Invalid limits,
+// cursors and unknown fields result in an error.
+func ParseGroupQuery(params url.Values) (*GroupQuery, error) {
+	query := &GroupQuery{
+		Query:        params.Get("q"),
+		Prefix:       params.Get("prefix"),
+		Domain:       params.Get("domain"),
+		MemberRole:   params.Get("member_role"),
+		MemberStatus: params.Get("member_status"),
+		MemberType:   params.Get("member_type"),
+		Settings:     NewSettingsFilter(params),
+		Cursor:       params.Get("cursor"),
+		Limit:        DefaultPageSize,
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > MaxPageSize {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+		}
+	}
+	if query.Cursor != "" {
+		if _, err := decodeCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	if fields := params.Get("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if _, ok := groupFields[field]; !ok {
+				return nil, fmt.Errorf("unknown field %s", field)
+			}
+			query.Fields = append(query.Fields, field)
+		}
+	}
+	return query, nil
+}
+
+// Search returns the page of groups matching the query. The groups are never
+// modified, groups with restricted members or fields are copies.
+func (q *GroupQuery) Search(groups map[string]*Group) *GroupPage {
+	matches := make([]*Group, 0)
+	for _, group := range groups {
+		if !q.matchesGroup(group) {
+			continue
+		}
+		if q.hasMemberFilter() {
+			group = q.withMatchingMembers(group)
+			if len(group.Members) == 0 {
+				continue
+			}
+		}
+		matches = append(matches, group)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return groupSortKey(matches[i]) < groupSortKey(matches[j])
+	})
+
+	page := &GroupPage{Total: len(matches)}
+
+	start := 0
+	if q.Cursor != "" {
+		after, _ := decodeCursor(q.Cursor)
+		start = sort.Search(len(matches), func(i int) bool {
+			return groupSortKey(matches[i]) > after
+		})
+	}
+	end := start + q.Limit
+	if end >= len(matches) {
+		end = len(matches)
+	} else {
+		page.NextCursor = encodeCursor(groupSortKey(matches[end-1]))
+	}
+
+	page.Groups = make([]*Group, 0, end-start)
+	for _, group := range matches[start:end] {
+		page.Groups = append(page.Groups, q.project(group))
+	}
+	return page
+}
+
+func (q *GroupQuery) matchesGroup(group *Group) bool {
+	texts := append([]string{group.Name, group.Email}, group.Aliases...)
+	if q.Query != "" && !anyText(texts, q.Query, strings.Contains) {
+		return false
+	}
+	if q.Prefix != "" && !anyText(texts, q.Prefix, strings.HasPrefix) {
+		return false
+	}
+	if q.Domain != "" && !strings.HasSuffix(strings.ToLower(group.Email), "@"+strings.ToLower(q.Domain)) {
+		return false
+	}
+	return q.Settings.Matches(group)
+}
+
+func anyText(texts []string, value string, match func(s string, value string) bool) bool {
+	value = strings.ToLower(value)
+	for _, text := range texts {
+		if match(strings.ToLower(text), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *GroupQuery) hasMemberFilter() bool {
+	return q.MemberRole != "" || q.MemberStatus != "" || q.MemberType != ""
+}
+
+func (q *GroupQuery) withMatchingMembers(group *Group) *Group {
+	groupCopy := *group
+	groupCopy.Members = map[string]*Member{}
+	for id, member := range group.Members {
+		if q.MemberRole != "" && !strings.EqualFold(member.Role, q.MemberRole) {
+			continue
+		}
+		if q.MemberStatus != "" && !strings.EqualFold(member.Status, q.MemberStatus) {
+			continue
+		}
+		if q.MemberType != "" && !strings.EqualFold(member.Type, q.MemberType) {
+			continue
+		}
+		groupCopy.Members[id] = member
+	}
+	return &groupCopy
+}
+
+func (q *GroupQuery) project(group *Group) *Group {
+	if len(q.Fields) == 0 {
+		return group
+	}
+	projected := &Group{}
+	for _, field := range q.Fields {
+		groupFields[field](projected, group)
+	}
+	return projected
+}
+
+// groupSortKey orders groups by their lower case email and id.
+func groupSortKey(group *Group) string {
+	return strings.ToLower(group.Email) + "\x00" + group.Id
+}
+
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.New("invalid cursor")
+	}
+	return string(key), nil
+}
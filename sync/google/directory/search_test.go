@@ -0,0 +1,53 @@
+package directory
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestSearchGroups(t *testing.T) {
+	a := assert.New(t)
+
+	groups := map[string]*Group{}
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("g%d", i)
+		groups[id] = &Group{Id: id, Name: "Team " + id, Email: id + "@your.org", Members: map[string]*Member{
+			"u1": {Id: "u1", Role: OwnerRole, Type: UserType},
+			"u2": {Id: "u2", Role: MemberRole, Type: UserType},
+		}}
+	}
+	groups["x"] = &Group{Id: "x", Name: "Other", Email: "x@other.org"}
+
+	query, err := ParseGroupQuery(url.Values{"q": {"TEAM"}, "limit": {"2"}, "fields": {"id,members"}})
+	a.NoError(err)
+
+	var ids []string
+	for {
+		page := query.Search(groups)
+		a.Equal(5, page.Total)
+		for _, group := range page.Groups {
+			ids = append(ids, group.Id)
+			a.Empty(group.Email)
+			a.Len(group.Members, 2)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	a.Equal([]string{"g0", "g1", "g2", "g3", "g4"}, ids)
+
+	query, err = ParseGroupQuery(url.Values{"domain": {"your.org"}, "member_role": {"owner"}, "prefix": {"g3"}})
+	a.NoError(err)
+	page := query.Search(groups)
+	a.Len(page.Groups, 1)
+	a.Len(page.Groups[0].Members, 1)
+	a.Len(groups["g3"].Members, 2)
+
+	_, err = ParseGroupQuery(url.Values{"fields": {"unknown"}})
+	a.Error(err)
+	_, err = ParseGroupQuery(url.Values{"limit": {"0"}})
+	a.Error(err)
+}
@@ -0,0 +1,129 @@
+package directory
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/groupssettings/v1"
+)
+
+// GroupSettings are the access and posting policies of a group as provided by
+// the groups settings API.
+type GroupSettings struct {
+	WhoCanJoin                 string `json:"who_can_join,omitempty"`
+	WhoCanViewMembership       string `json:"who_can_view_membership,omitempty"`
+	WhoCanViewGroup            string `json:"who_can_view_group,omitempty"`
+	WhoCanInvite               string `json:"who_can_invite,omitempty"`
+	WhoCanAdd                  string `json:"who_can_add,omitempty"`
+	WhoCanPostMessage          string `json:"who_can_post_message,omitempty"`
+	WhoCanLeaveGroup           string `json:"who_can_leave_group,omitempty"`
+	WhoCanContactOwner         string `json:"who_can_contact_owner,omitempty"`
+	MessageModerationLevel     string `json:"message_moderation_level,omitempty"`
+	SpamModerationLevel        string `json:"spam_moderation_level,omitempty"`
+	AllowExternalMembers       bool   `json:"allow_external_members"`
+	AllowWebPosting            bool   `json:"allow_web_posting"`
+	MembersCanPostAsTheGroup   bool   `json:"members_can_post_as_the_group"`
+	IncludeInGlobalAddressList bool   `json:"include_in_global_address_list"`
+	ShowInGroupDirectory       bool   `json:"show_in_group_directory"`
+	ArchiveOnly                bool   `json:"archive_only"`
+	IsArchived                 bool   `json:"is_archived"`
+}
+
+// settingsFilters maps the filterable settings to their value.
+var settingsFilters = map[string]func(s *GroupSettings) string{
+	"who_can_join":                   func(s *GroupSettings) string { return s.WhoCanJoin },
+	"who_can_view_membership":        func(s *GroupSettings) string { return s.WhoCanViewMembership },
+	"who_can_view_group":             func(s *GroupSettings) string { return s.WhoCanViewGroup },
+	"who_can_invite":                 func(s *GroupSettings) string { return s.WhoCanInvite },
+	"who_can_add":                    func(s *GroupSettings) string { return s.WhoCanAdd },
+	"who_can_post_message":           func(s *GroupSettings) string { return s.WhoCanPostMessage },
+	"who_can_leave_group":            func(s *GroupSettings) string { return s.WhoCanLeaveGroup },
+	"who_can_contact_owner":          func(s *GroupSettings) string { return s.WhoCanContactOwner },
+	"message_moderation_level":       func(s *GroupSettings) string { return s.MessageModerationLevel },
+	"spam_moderation_level":          func(s *GroupSettings) string { return s.SpamModerationLevel },
+	"allow_external_members":         func(s *GroupSettings) string { return strconv.FormatBool(s.AllowExternalMembers) },
+	"allow_web_posting":              func(s *GroupSettings) string { return strconv.FormatBool(s.AllowWebPosting) },
+	"members_can_post_as_the_group":  func(s *GroupSettings) string { return strconv.FormatBool(s.MembersCanPostAsTheGroup) },
+	"include_in_global_address_list": func(s *GroupSettings) string { return strconv.FormatBool(s.IncludeInGlobalAddressList) },
+	"show_in_group_directory":        func(s *GroupSettings) string { return strconv.FormatBool(s.ShowInGroupDirectory) },
+	"archive_only":                   func(s *GroupSettings) string { return strconv.FormatBool(s.ArchiveOnly) },
+	"is_archived":                    func(s *GroupSettings) string { return strconv.FormatBool(s.IsArchived) },
+}
+
+// SettingsFilter selects groups by their settings. Every entry maps the json
+// name of a setting to the required value. Values are compared case
+// insensitive.
+type SettingsFilter map[string]string
+
+// NewSettingsFilter returns a filter for all known settings among the given
+// parameters. Other parameters are ignored.
+func NewSettingsFilter(params map[string][]string) SettingsFilter {
+	filter := SettingsFilter{}
+	for name, values := range params {
+		if _, ok := settingsFilters[name]; ok && len(values) > 0 {
+			filter[name] = values[0]
+		}
+	}
+	return filter
+}
+
+// Matches reports whether the settings of the group satisfy the filter. Groups
+// without settings never match a non empty filter.
+func (f SettingsFilter) Matches(group *Group) bool {
+	if len(f) == 0 {
+		return true
+	}
+	if group.Settings == nil {
+		return false
+	}
+	for name, value := range f {
+		if !strings.EqualFold(settingsFilters[name](group.Settings), value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Service) retrieveSettings(ctx context.Context, groupEmail string) (*GroupSettings, error) {
+	getCall := c.settingsService.Groups.Get(groupEmail).Context(ctx)
+
+	var settings *groupssettings.Groups
+	err := c.scheduler.Do(ctx, func() error {
+		var err error
+		settings, err = getCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toGroupSettings(settings), nil
+}
+
+func toGroupSettings(settings *groupssettings.Groups) *GroupSettings {
+	return &GroupSettings{
+		WhoCanJoin:                 settings.WhoCanJoin,
+		WhoCanViewMembership:       settings.WhoCanViewMembership,
+		WhoCanViewGroup:            settings.WhoCanViewGroup,
+		WhoCanInvite:               settings.WhoCanInvite,
+		WhoCanAdd:                  settings.WhoCanAdd,
+		WhoCanPostMessage:          settings.WhoCanPostMessage,
+		WhoCanLeaveGroup:           settings.WhoCanLeaveGroup,
+		WhoCanContactOwner:         settings.WhoCanContactOwner,
+		MessageModerationLevel:     settings.MessageModerationLevel,
+		SpamModerationLevel:        settings.SpamModerationLevel,
+		AllowExternalMembers:       isTrue(settings.AllowExternalMembers),
+		AllowWebPosting:            isTrue(settings.AllowWebPosting),
+		MembersCanPostAsTheGroup:   isTrue(settings.MembersCanPostAsTheGroup),
+		IncludeInGlobalAddressList: isTrue(settings.IncludeInGlobalAddressList),
+		ShowInGroupDirectory:       isTrue(settings.ShowInGroupDirectory),
+		ArchiveOnly:                isTrue(settings.ArchiveOnly),
+		IsArchived:                 isTrue(settings.IsArchived),
+	}
+}
+
+// isTrue parses the boolean settings which the API returns as strings.
+func isTrue(value string) bool {
+	return strings.EqualFold(value, "true")
+}
@@ -0,0 +1,26 @@
+package directory
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSettingsFilter(t *testing.T) {
+	a := assert.New(t)
+
+	open := &Group{Settings: &GroupSettings{WhoCanJoin: "ANYONE_CAN_JOIN", AllowExternalMembers: true}}
+	closed := &Group{Settings: &GroupSettings{WhoCanJoin: "INVITED_CAN_JOIN"}}
+	unknown := &Group{}
+
+	filter := NewSettingsFilter(map[string][]string{
+		"allow_external_members": {"TRUE"},
+		"names":                  {"true"},
+	})
+	a.Len(filter, 1)
+	a.True(filter.Matches(open))
+	a.False(filter.Matches(closed))
+	a.False(filter.Matches(unknown))
+
+	a.True(NewSettingsFilter(nil).Matches(unknown))
+	a.False(NewSettingsFilter(map[string][]string{"who_can_join": {"anyone_can_join"}}).Matches(closed))
+}
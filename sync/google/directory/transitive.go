@@ -0,0 +1,117 @@
+package directory
+
+import (
+	"sort"
+)
+
+// DefaultMaxNestingDepth is the default number of nested groups followed when
+// resolving transitive memberships.
+const DefaultMaxNestingDepth = 10
+
+// Membership is the membership in a group, either directly with a depth of 1
+// or through Depth-1 nested groups.
+type Membership struct {
+	GroupId string `json:"group_id"`
+	Depth   int    `json:"depth"`
+}
+
+// EffectiveMember is a direct or nested member of a group. Depth is 1 for
+// direct members and increases with every nested group in between.
+type EffectiveMember struct {
+	Id    string `json:"id"`
+	Email string `json:"email,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Depth int    `json:"depth"`
+}
+
+// Closure is the transitive closure of the group memberships.
+type Closure struct {
+	// Memberships are the direct and nested memberships of every member id,
+	// sorted by depth and group id.
+	Memberships map[string][]Membership
+	// Members are the direct and nested members of every group id, sorted by
+	// depth and member id.
+	Members map[string][]EffectiveMember
+	// Cycles contains the sorted ids of all groups that are nested within
+	// themselves.
+	Cycles []string
+}
+
+// ToClosure resolves the memberships of all members through nested groups.
+// Every membership is reported once with the shortest nesting depth. Cycles
+// are followed only once. Memberships nested deeper than maxDepth are not
+// resolved, a maxDepth of 0 disables the limit.
+func ToClosure(groups map[string]*Group, maxDepth int) *Closure {
+	parents := ToMemberIdGroupIdsMapping(groups)
+
+	closure := &Closure{
+		Memberships: make(map[string][]Membership, len(parents)),
+		Members:     make(map[string][]EffectiveMember, len(groups)),
+		Cycles:      []string{},
+	}
+
+	members := map[string]*Member{}
+	for _, group := range groups {
+		for id, member := range group.Members {
+			members[id] = member
+		}
+	}
+
+	for memberId := range parents {
+		depths := resolveDepths(parents, memberId, maxDepth)
+		if _, ok := depths[memberId]; ok {
+			closure.Cycles = append(closure.Cycles, memberId)
+		}
+
+		memberships := make([]Membership, 0, len(depths))
+		for groupId, depth := range depths {
+			memberships = append(memberships, Membership{GroupId: groupId, Depth: depth})
+
+			member := members[memberId]
+			closure.Members[groupId] = append(closure.Members[groupId], EffectiveMember{
+				Id:    memberId,
+				Email: member.Email,
+				Type:  member.Type,
+				Depth: depth,
+			})
+		}
+		sort.Slice(memberships, func(i, j int) bool {
+			if memberships[i].Depth != memberships[j].Depth {
+				return memberships[i].Depth < memberships[j].Depth
+			}
+			return memberships[i].GroupId < memberships[j].GroupId
+		})
+		closure.Memberships[memberId] = memberships
+	}
+
+	for _, effectiveMembers := range closure.Members {
+		sort.Slice(effectiveMembers, func(i, j int) bool {
+			if effectiveMembers[i].Depth != effectiveMembers[j].Depth {
+				return effectiveMembers[i].Depth < effectiveMembers[j].Depth
+			}
+			return effectiveMembers[i].Id < effectiveMembers[j].Id
+		})
+	}
+	sort.Strings(closure.Cycles)
+
+	return closure
+}
+
+// resolveDepths walks up the nested groups of the member breadth first and
+// returns the shortest depth of every group reached.
+func resolveDepths(parents map[string][]string, memberId string, maxDepth int) map[string]int {
+	depths := map[string]int{}
+	frontier := parents[memberId]
+	for depth := 1; len(frontier) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		var next []string
+		for _, groupId := range frontier {
+			if _, seen := depths[groupId]; seen {
+				continue
+			}
+			depths[groupId] = depth
+			next = append(next, parents[groupId]...)
+		}
+		frontier = next
+	}
+	return depths
+}
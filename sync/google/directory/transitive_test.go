@@ -0,0 +1,37 @@
+package directory
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransitiveMemberships(t *testing.T) {
+	a := assert.New(t)
+
+	member := func(id string, memberType string) *Member {
+		return &Member{Id: id, Email: id + "@your.org", Type: memberType}
+	}
+	groups := map[string]*Group{
+		"g1": {Id: "g1", Members: map[string]*Member{"u1": member("u1", UserType)}},
+		"g2": {Id: "g2", Members: map[string]*Member{"g1": member("g1", GroupType)}},
+		"g3": {Id: "g3", Members: map[string]*Member{"g2": member("g2", GroupType), "u1": member("u1", UserType)}},
+		"g4": {Id: "g4", Members: map[string]*Member{"g3": member("g3", GroupType)}},
+		"g5": {Id: "g5", Members: map[string]*Member{"g6": member("g6", GroupType)}},
+		"g6": {Id: "g6", Members: map[string]*Member{"g5": member("g5", GroupType)}},
+	}
+
+	closure := ToClosure(groups, 0)
+	a.Equal([]Membership{
+		{GroupId: "g1", Depth: 1},
+		{GroupId: "g3", Depth: 1},
+		{GroupId: "g2", Depth: 2},
+		{GroupId: "g4", Depth: 2},
+	}, closure.Memberships["u1"])
+	a.Equal([]string{"g5", "g6"}, closure.Cycles)
+	a.Equal([]Membership{{GroupId: "g6", Depth: 1}, {GroupId: "g5", Depth: 2}}, closure.Memberships["g5"])
+	a.Len(closure.Members["g4"], 4)
+	a.Equal(EffectiveMember{Id: "g3", Email: "g3@your.org", Type: GroupType, Depth: 1}, closure.Members["g4"][0])
+
+	limited := ToClosure(groups, 1)
+	a.Equal([]Membership{{GroupId: "g1", Depth: 1}, {GroupId: "g3", Depth: 1}}, limited.Memberships["u1"])
+}
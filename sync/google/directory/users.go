@@ -0,0 +1,162 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	UserType = "USER"
+)
+
+type User struct {
+	Id                 string   `json:"id,omitempty"`
+	PrimaryEmail       string   `json:"primary_email,omitempty"`
+	Name               string   `json:"name,omitempty"`
+	GivenName          string   `json:"given_name,omitempty"`
+	FamilyName         string   `json:"family_name,omitempty"`
+	Aliases            []string `json:"aliases,omitempty"`
+	NonEditableAliases []string `json:"non_editable_aliases,omitempty"`
+	OrgUnitPath        string   `json:"org_unit_path,omitempty"`
+	Suspended          bool     `json:"suspended"`
+	Archived           bool     `json:"archived"`
+	IsAdmin            bool     `json:"is_admin"`
+	IsDelegatedAdmin   bool     `json:"is_delegated_admin"`
+	ETag               string   `json:"etag,omitempty"`
+}
+
+// Emails returns the primary email address and all aliases of the user.
+func (u *User) Emails() []string {
+	emails := make([]string, 0, 1+len(u.Aliases)+len(u.NonEditableAliases))
+	emails = append(emails, u.PrimaryEmail)
+	emails = append(emails, u.Aliases...)
+	emails = append(emails, u.NonEditableAliases...)
+	return emails
+}
+
+// apiUser extends the user of the vendored admin client, which predates the
+// archived flag. Users are therefore listed with a plain request.
+type apiUser struct {
+	admin.User
+	Archived bool `json:"archived,omitempty"`
+}
+
+type apiUsers struct {
+	Users         []*apiUser `json:"users,omitempty"`
+	NextPageToken string     `json:"nextPageToken,omitempty"`
+}
+
+func (c *Service) RetrieveUsers(ctx context.Context) (map[string]*User, error) {
+	completeUsers := map[string]*User{}
+	nextPageToken := ""
+
+	var users *apiUsers
+	var err error
+
+	for {
+		users, nextPageToken, err = c.userCall(ctx, nextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range users.Users {
+			completeUsers[k.Id] = toUser(k)
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+	}
+
+	return completeUsers, nil
+}
+
+func toUser(user *apiUser) *User {
+	result := &User{
+		Id:                 user.Id,
+		PrimaryEmail:       user.PrimaryEmail,
+		Aliases:            user.Aliases,
+		NonEditableAliases: user.NonEditableAliases,
+		OrgUnitPath:        user.OrgUnitPath,
+		Suspended:          user.Suspended,
+		Archived:           user.Archived,
+		IsAdmin:            user.IsAdmin,
+		IsDelegatedAdmin:   user.IsDelegatedAdmin,
+		ETag:               user.Etag,
+	}
+	if user.Name != nil {
+		result.Name = user.Name.FullName
+		result.GivenName = user.Name.GivenName
+		result.FamilyName = user.Name.FamilyName
+	}
+	return result
+}
+
+func (c *Service) userCall(ctx context.Context, pageToken string) (*apiUsers, string, error) {
+	params := url.Values{}
+	params.Set("customer", c.customerId)
+	params.Set("projection", "basic")
+	params.Set("maxResults", "500")
+	if pageToken != "" {
+		params.Set("pageToken", pageToken)
+	}
+	if c.domain != "" {
+		params.Set("domain", c.domain)
+	}
+	listUrl := googleapi.ResolveRelative(c.directoryService.BasePath, "users") + "?" + params.Encode()
+
+	var users *apiUsers
+	err := c.scheduler.Do(ctx, func() error {
+		req, err := http.NewRequest("GET", listUrl, nil)
+		if err != nil {
+			return err
+		}
+		res, err := c.httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer googleapi.CloseBody(res)
+		if err := googleapi.CheckResponse(res); err != nil {
+			return err
+		}
+
+		users = &apiUsers{}
+		return json.NewDecoder(res.Body).Decode(users)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return users, users.NextPageToken, nil
+}
+
+// WithMemberNames returns a copy of the groups in which every member carries
+// the display name of the user or group it refers to. The given groups are not
+// modified.
+func WithMemberNames(groups map[string]*Group, users map[string]*User) map[string]*Group {
+	result := make(map[string]*Group, len(groups))
+	for id, group := range groups {
+		groupCopy := *group
+		groupCopy.Members = make(map[string]*Member, len(group.Members))
+		for memberId, member := range group.Members {
+			memberCopy := *member
+			switch member.Type {
+			case UserType:
+				if user, ok := users[member.Id]; ok {
+					memberCopy.Name = user.Name
+				}
+			case GroupType:
+				if memberGroup, ok := groups[member.Id]; ok {
+					memberCopy.Name = memberGroup.Name
+				}
+			}
+			groupCopy.Members[memberId] = &memberCopy
+		}
+		result[id] = &groupCopy
+	}
+	return result
+}
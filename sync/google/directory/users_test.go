@@ -0,0 +1,59 @@
+package directory
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/googletest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/admin/directory/v1"
+)
+
+func TestRetrieveUsers(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.AddUser(&admin.User{
+		Id:           "u1",
+		PrimaryEmail: "jane@your.org",
+		Name:         &admin.UserName{FullName: "Jane Doe", GivenName: "Jane", FamilyName: "Doe"},
+		Aliases:      []string{"j.doe@your.org"},
+		OrgUnitPath:  "/Engineering",
+		IsAdmin:      true,
+	})
+	server.AddUser(map[string]interface{}{
+		"id":           "u2",
+		"primaryEmail": "john@your.org",
+		"suspended":    true,
+		"archived":     true,
+	})
+	var query map[string][]string
+	server.Hook = func(r *http.Request) {
+		query = r.URL.Query()
+	}
+	service := newTestService(t, server, 1, false)
+
+	users, err := service.RetrieveUsers(context.Background())
+	a.NoError(err)
+	a.Equal([]string{"customer"}, query["customer"])
+	a.Len(users, 2)
+	a.Equal("Jane Doe", users["u1"].Name)
+	a.Equal("Doe", users["u1"].FamilyName)
+	a.Equal([]string{"jane@your.org", "j.doe@your.org"}, users["u1"].Emails())
+	a.Equal("/Engineering", users["u1"].OrgUnitPath)
+	a.True(users["u1"].IsAdmin)
+	a.False(users["u1"].Suspended)
+	a.True(users["u2"].Suspended)
+	a.True(users["u2"].Archived)
+
+	groups := WithMemberNames(map[string]*Group{
+		"g1": {Id: "g1", Name: "Team", Members: map[string]*Member{"u1": {Id: "u1", Type: UserType}}},
+	}, users)
+	a.Equal("Jane Doe", groups["g1"].Members["u1"].Name)
+
+	server.Fail("/admin/directory/v1/users", 403)
+	_, err = service.RetrieveUsers(context.Background())
+	a.Error(err)
+}
@@ -0,0 +1,214 @@
+// Package googletest provides a fake of the Google APIs used by the sync for
+// tests.
+package googletest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	"google.golang.org/api/groupssettings/v1"
+)
+
+const (
+	directoryPath = "/admin/directory/v1/"
+	settingsPath  = "/groups/v1/groups/"
+	activityPath  = "/admin/reports/v1/activity/users/all/applications/groups"
+)
+
+// Server answers the calls of the directory, groups settings and reports
+// services with the data added to it. Every group is returned on the first
+// page, paging is not supported.
+type Server struct {
+	*httptest.Server
+	// Hook is called with every request before it is answered.
+	Hook func(r *http.Request)
+
+	mutex      sync.Mutex
+	groups     map[string]*admin.Group
+	members    map[string][]*admin.Member
+	settings   map[string]*groupssettings.Groups
+	users      []interface{}
+	activities []*reports.Activity
+	failures   map[string]int
+	calls      map[string]int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		groups:   map[string]*admin.Group{},
+		members:  map[string][]*admin.Member{},
+		settings: map[string]*groupssettings.Groups{},
+		failures: map[string]int{},
+		calls:    map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns a client that sends the requests for all hosts to the server.
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: &redirectTransport{target: target}}
+}
+
+// SetGroup adds or replaces the group together with its members.
+func (s *Server) SetGroup(group *admin.Group, members ...*admin.Member) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.groups[group.Id] = group
+	s.members[group.Id] = members
+}
+
+// SetSettings sets the settings of the group with the given email address.
+func (s *Server) SetSettings(email string, settings *groupssettings.Groups) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings[email] = settings
+}
+
+// AddUser adds a user. Any value that encodes to a user of the directory API
+// can be used, which allows fields the vendored client does not know.
+func (s *Server) AddUser(user interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.users = append(s.users, user)
+}
+
+// AddGroupEvent adds an event of the groups activity feed for the group with
+// the given email address.
+func (s *Server) AddGroupEvent(name string, groupEmail string, eventTime time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.activities = append(s.activities, &reports.Activity{
+		Id: &reports.ActivityId{
+			Time:            eventTime.UTC().Format(time.RFC3339),
+			UniqueQualifier: int64(len(s.activities) + 1),
+		},
+		Events: []*reports.ActivityEvents{{
+			Name:       name,
+			Parameters: []*reports.ActivityEventsParameters{{Name: "group_email", Value: groupEmail}},
+		}},
+	})
+}
+
+// Fail answers all following requests for the path with the status code. A
+// status code of 0 answers them normally again.
+func (s *Server) Fail(path string, statusCode int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if statusCode == 0 {
+		delete(s.failures, path)
+		return
+	}
+	s.failures[path] = statusCode
+}
+
+// Calls returns the number of requests for the path.
+func (s *Server) Calls(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls[path]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if s.Hook != nil {
+		s.Hook(r)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls[r.URL.Path]++
+	if statusCode, ok := s.failures[r.URL.Path]; ok {
+		writeError(w, statusCode)
+		return
+	}
+
+	switch {
+	case r.URL.Path == activityPath:
+		writeJson(w, &reports.Activities{Items: s.activities})
+	case strings.HasPrefix(r.URL.Path, settingsPath):
+		settings, ok := s.settings[strings.TrimPrefix(r.URL.Path, settingsPath)]
+		if !ok {
+			settings = &groupssettings.Groups{}
+		}
+		writeJson(w, settings)
+	case r.URL.Path == directoryPath+"groups":
+		groups := &admin.Groups{Groups: []*admin.Group{}}
+		for _, group := range s.groups {
+			groups.Groups = append(groups.Groups, group)
+		}
+		sort.Slice(groups.Groups, func(i, j int) bool {
+			return groups.Groups[i].Id < groups.Groups[j].Id
+		})
+		writeJson(w, groups)
+	case r.URL.Path == directoryPath+"users":
+		writeJson(w, map[string]interface{}{"users": s.users})
+	case strings.HasPrefix(r.URL.Path, directoryPath+"groups/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, directoryPath+"groups/"), "/")
+		group := s.group(parts[0])
+		switch {
+		case group == nil:
+			writeError(w, http.StatusNotFound)
+		case len(parts) == 1:
+			writeJson(w, group)
+		case len(parts) == 2 && parts[1] == "members":
+			writeJson(w, &admin.Members{Members: s.members[group.Id]})
+		default:
+			writeError(w, http.StatusNotFound)
+		}
+	default:
+		writeError(w, http.StatusNotFound)
+	}
+}
+
+// group finds the group by id or email address.
+func (s *Server) group(key string) *admin.Group {
+	if group, ok := s.groups[key]; ok {
+		return group
+	}
+	for _, group := range s.groups {
+		if strings.EqualFold(group.Email, key) {
+			return group
+		}
+	}
+	return nil
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    statusCode,
+			"message": http.StatusText(statusCode),
+		},
+	})
+}
+
+// redirectTransport sends every request to the target host.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	redirected := new(http.Request)
+	*redirected = *r
+	redirected.URL = new(url.URL)
+	*redirected.URL = *r.URL
+	redirected.URL.Scheme = t.target.Scheme
+	redirected.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(redirected)
+}
@@ -0,0 +1,192 @@
+package quota
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// Scheduler executes API calls within a shared queries per second budget and
+// retries calls that failed with a retryable error.
+type Scheduler struct {
+	bucket     *tokenBucket
+	maxRetries int
+
+	calls     int64
+	retries   int64
+	throttled int64
+	backoff   int64
+}
+
+type Stats struct {
+	Calls         int64
+	Retries       int64
+	ThrottledTime time.Duration
+	BackoffTime   time.Duration
+}
+
+// Sub returns the difference between two stats, which is used to get the
+// numbers of a single sync.
+func (s Stats) Sub(other Stats) Stats {
+	return Stats{
+		Calls:         s.Calls - other.Calls,
+		Retries:       s.Retries - other.Retries,
+		ThrottledTime: s.ThrottledTime - other.ThrottledTime,
+		BackoffTime:   s.BackoffTime - other.BackoffTime,
+	}
+}
+
+// New creates a scheduler that allows qps calls per second on average. A qps
+// of zero or less disables the rate limit.
+func New(qps float64, maxRetries int) *Scheduler {
+	var bucket *tokenBucket
+	if qps > 0 {
+		bucket = newTokenBucket(qps)
+	}
+	return &Scheduler{
+		bucket:     bucket,
+		maxRetries: maxRetries,
+	}
+}
+
+// Do runs the call once the rate limit permits it. Errors classified as
+// retryable are retried with exponential backoff and jitter until the retries
+// are exhausted or the context is done.
+func (s *Scheduler) Do(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if s.bucket != nil {
+			waited, err := s.bucket.wait(ctx)
+			atomic.AddInt64(&s.throttled, int64(waited))
+			if err != nil {
+				return err
+			}
+		}
+
+		atomic.AddInt64(&s.calls, 1)
+		err := call()
+		if err == nil || attempt >= s.maxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		atomic.AddInt64(&s.retries, 1)
+		delay := backoff(attempt)
+		atomic.AddInt64(&s.backoff, int64(delay))
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+func (s *Scheduler) Stats() Stats {
+	return Stats{
+		Calls:         atomic.LoadInt64(&s.calls),
+		Retries:       atomic.LoadInt64(&s.retries),
+		ThrottledTime: time.Duration(atomic.LoadInt64(&s.throttled)),
+		BackoffTime:   time.Duration(atomic.LoadInt64(&s.backoff)),
+	}
+}
+
+// IsRetryable reports whether the error is a temporary google API error, like
+// an exceeded rate limit or an unavailable backend.
+func IsRetryable(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		for _, item := range apiErr.Errors {
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry attempt. Half of the delay
+// is random to spread out retries of parallel workers.
+func backoff(attempt int) time.Duration {
+	delay := maxBackoff
+	if attempt < 16 {
+		delay = baseBackoff << uint(attempt)
+		if delay > maxBackoff {
+			delay = maxBackoff
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// tokenBucket hands out tokens at a fixed rate. Callers reserve a token up
+// front and wait until it becomes available, which keeps the order fair.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(qps float64) *tokenBucket {
+	burst := qps
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   qps,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) reserve() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) wait(ctx context.Context) (time.Duration, error) {
+	delay := b.reserve()
+	return delay, sleep(ctx, delay)
+}
@@ -0,0 +1,65 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsRetryable(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsRetryable(&googleapi.Error{Code: 503}))
+	a.True(IsRetryable(&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}))
+	a.False(IsRetryable(&googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}}))
+	a.False(IsRetryable(&googleapi.Error{Code: 404}))
+	a.False(IsRetryable(errors.New("some error")))
+}
+
+func TestDoRetriesRetryableErrors(t *testing.T) {
+	a := assert.New(t)
+
+	scheduler := New(0, 2)
+	attempts := 0
+	err := scheduler.Do(context.Background(), func() error {
+		attempts++
+		if attempts < 2 {
+			return &googleapi.Error{Code: 500}
+		}
+		return nil
+	})
+
+	a.Nil(err)
+	a.Equal(2, attempts)
+	a.EqualValues(2, scheduler.Stats().Calls)
+	a.EqualValues(1, scheduler.Stats().Retries)
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	a := assert.New(t)
+
+	scheduler := New(0, 5)
+	attempts := 0
+	err := scheduler.Do(context.Background(), func() error {
+		attempts++
+		return &googleapi.Error{Code: 404}
+	})
+
+	a.NotNil(err)
+	a.Equal(1, attempts)
+	a.EqualValues(0, scheduler.Stats().Retries)
+}
+
+func TestTokenBucketThrottles(t *testing.T) {
+	a := assert.New(t)
+
+	bucket := newTokenBucket(10)
+	for i := 0; i < 10; i++ {
+		a.Equal(time.Duration(0), bucket.reserve())
+	}
+	a.True(bucket.reserve() > 0)
+}
@@ -0,0 +1,130 @@
+package reports
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/quota"
+	"google.golang.org/api/admin/reports/v1"
+)
+
+const (
+	groupsApplication = "groups"
+
+	groupEmailParameter  = "group_email"
+	memberEmailParameter = "user_email"
+)
+
+type Service struct {
+	reportsService *admin.Service
+	scheduler      *quota.Scheduler
+}
+
+// GroupChange is a single event of the groups activity feed that affects the
+// group identified by GroupEmail.
+type GroupChange struct {
+	Key         string
+	Time        time.Time
+	Event       string
+	GroupEmail  string
+	MemberEmail string
+}
+
+func New(client *http.Client, scheduler *quota.Scheduler) (*Service, error) {
+	service, err := admin.New(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		reportsService: service,
+		scheduler:      scheduler,
+	}, nil
+}
+
+// RetrieveGroupChanges returns all events of the groups application that
+// happened at or after the given time, ordered by their occurrence.
+func (s *Service) RetrieveGroupChanges(ctx context.Context, since time.Time) ([]*GroupChange, error) {
+	changes := make([]*GroupChange, 0)
+	nextPageToken := ""
+
+	var activities *admin.Activities
+	var err error
+
+	for {
+		activities, nextPageToken, err = s.activityCall(ctx, since, nextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, activity := range activities.Items {
+			changes = append(changes, toGroupChanges(activity)...)
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
+
+func toGroupChanges(activity *admin.Activity) []*GroupChange {
+	if activity.Id == nil {
+		return nil
+	}
+	eventTime, err := time.Parse(time.RFC3339, activity.Id.Time)
+	if err != nil {
+		return nil
+	}
+	key := activity.Id.Time + "/" + strconv.FormatInt(activity.Id.UniqueQualifier, 10)
+
+	changes := make([]*GroupChange, 0, len(activity.Events))
+	for _, event := range activity.Events {
+		change := &GroupChange{
+			Key:   key,
+			Time:  eventTime,
+			Event: event.Name,
+		}
+		for _, parameter := range event.Parameters {
+			switch parameter.Name {
+			case groupEmailParameter:
+				change.GroupEmail = parameter.Value
+			case memberEmailParameter:
+				change.MemberEmail = parameter.Value
+			}
+		}
+		if change.GroupEmail != "" {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func (s *Service) activityCall(ctx context.Context, since time.Time, pageToken string) (*admin.Activities, string, error) {
+	listCall := s.reportsService.Activities.List("all", groupsApplication).
+		StartTime(since.UTC().Format(time.RFC3339)).
+		MaxResults(1000).
+		Context(ctx)
+	if pageToken != "" {
+		listCall = listCall.PageToken(pageToken)
+	}
+
+	var activities *admin.Activities
+	err := s.scheduler.Do(ctx, func() error {
+		var err error
+		activities, err = listCall.Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return activities, activities.NextPageToken, nil
+}
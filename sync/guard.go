@@ -0,0 +1,179 @@
+package sync
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/sirupsen/logrus"
+)
+
+// Thresholds define the maximum drop in percent between the published and a
+// new snapshot. A threshold of zero disables the check.
+type Thresholds struct {
+	MaxGroupDrop      float64
+	MaxMemberDrop     float64
+	MaxMembershipDrop float64
+}
+
+type Violation struct {
+	Metric   string  `json:"metric"`
+	Previous int     `json:"previous"`
+	Current  int     `json:"current"`
+	Drop     float64 `json:"drop_percent"`
+	MaxDrop  float64 `json:"max_drop_percent"`
+}
+
+var (
+	ErrNoQuarantine       = errors.New("no directory is quarantined")
+	ErrQuarantineOutdated = errors.New("the quarantined directory is outdated by a newer snapshot")
+)
+
+// Quarantine holds a synced directory that was not published because it
+// dropped more data than allowed. It is only published after a confirmation
+// and only as long as the snapshot it was compared against is still current.
+type Quarantine struct {
+	Created time.Time `json:"created"`
+	// BaseGeneration is the generation of the snapshot the quarantined
+	// directory was compared against
+	BaseGeneration   uint64       `json:"base_generation"`
+	Violations       []*Violation `json:"violations"`
+	KnownGroups      int          `json:"known_groups"`
+	KnownMembers     int          `json:"known_members"`
+	KnownMemberships int          `json:"known_memberships"`
+
+	Data Data `json:"-"`
+}
+
+func checkThresholds(previous *Snapshot, next *Snapshot, thresholds Thresholds) []*Violation {
+	violations := make([]*Violation, 0)
+	check := func(metric string, previous int, current int, maxDrop float64) {
+		if maxDrop <= 0 || previous == 0 || current >= previous {
+			return
+		}
+		drop := float64(previous-current) / float64(previous) * 100
+		if drop > maxDrop {
+			violations = append(violations, &Violation{
+				Metric:   metric,
+				Previous: previous,
+				Current:  current,
+				Drop:     drop,
+				MaxDrop:  maxDrop,
+			})
+		}
+	}
+
+	check("groups", len(previous.Groups), len(next.Groups), thresholds.MaxGroupDrop)
+	check("members", len(previous.MemberIdToGroupIds), len(next.MemberIdToGroupIds), thresholds.MaxMemberDrop)
+	check("memberships", previous.KnownMembers(), next.KnownMembers(), thresholds.MaxMembershipDrop)
+	return violations
+}
+
+// publishGuarded publishes the data unless it violates the thresholds
+// compared to the current snapshot, in which case it is quarantined and nil
+// is returned. A successful publish drops an earlier quarantine, as it is
+// outdated by the newer directory.
+func (d *dirSync) publishGuarded(data Data) *Snapshot {
+	return d.publishGuardedWith(func(current *Snapshot) Data {
+		return data
+	})
+}
+
+// publishGuardedWith builds the data from the current snapshot and publishes
+// it like publishGuarded. The publishMutex is held while building, so a
+// snapshot published concurrently is never overwritten by data built from an
+// older one.
+func (d *dirSync) publishGuardedWith(build func(current *Snapshot) Data) *Snapshot {
+	d.publishMutex.Lock()
+	defer d.publishMutex.Unlock()
+
+	// Only the figures compared by the thresholds are derived for the
+	// candidate, the full snapshot is built when it is published.
+	current := d.Snapshot()
+	data := build(current)
+	candidate := &Snapshot{
+		Data:               data,
+		MemberIdToGroupIds: directory.ToMemberIdGroupIdsMapping(data.Groups),
+	}
+	violations := checkThresholds(current, candidate, d.thresholds)
+	if len(violations) == 0 {
+		snapshot := d.publishLocked(data)
+		if d.takeQuarantine() != nil {
+			logrus.Infof("Dropped the quarantined directory after publishing generation %d", snapshot.Generation)
+		}
+		return snapshot
+	}
+
+	quarantine := &Quarantine{
+		Created:          time.Now(),
+		BaseGeneration:   current.Generation,
+		Violations:       violations,
+		KnownGroups:      len(candidate.Groups),
+		KnownMembers:     len(candidate.MemberIdToGroupIds),
+		KnownMemberships: candidate.KnownMembers(),
+		Data:             data,
+	}
+	for _, violation := range violations {
+		logrus.Errorf("Quarantined synced directory: %s dropped from %d to %d (%.1f%%, max %.1f%%)",
+			violation.Metric, violation.Previous, violation.Current, violation.Drop, violation.MaxDrop)
+	}
+
+	d.quarantineMutex.Lock()
+	d.quarantine = quarantine
+	d.updateStatus(func(status *Status) {
+		status.Quarantined = true
+	})
+	d.quarantineMutex.Unlock()
+	return nil
+}
+
+func (d *dirSync) Quarantine() *Quarantine {
+	d.quarantineMutex.Lock()
+	defer d.quarantineMutex.Unlock()
+	return d.quarantine
+}
+
+// ApplyQuarantine publishes the quarantined directory after an explicit
+// confirmation. A quarantine whose base generation is no longer current is
+// dropped instead, publishing it would roll back the newer snapshot.
+func (d *dirSync) ApplyQuarantine() error {
+	quarantine := d.takeQuarantine()
+	if quarantine == nil {
+		return ErrNoQuarantine
+	}
+
+	d.publishMutex.Lock()
+	if d.generation != quarantine.BaseGeneration {
+		d.publishMutex.Unlock()
+		logrus.Warnf("Dropped the quarantined directory of generation %d, the current generation is %d",
+			quarantine.BaseGeneration, d.Snapshot().Generation)
+		return ErrQuarantineOutdated
+	}
+	logrus.Warnf("Publishing quarantined directory with %d groups after confirmation", quarantine.KnownGroups)
+	snapshot := d.publishLocked(quarantine.Data)
+	d.publishMutex.Unlock()
+
+	err := d.persistToDisk(d.storageLocation, snapshot)
+	if err != nil {
+		logrus.Warnf("Failed to persist directory to disk: %v", err)
+	}
+	return nil
+}
+
+// DiscardQuarantine drops the quarantined directory. It returns false if
+// nothing is quarantined.
+func (d *dirSync) DiscardQuarantine() bool {
+	return d.takeQuarantine() != nil
+}
+
+func (d *dirSync) takeQuarantine() *Quarantine {
+	d.quarantineMutex.Lock()
+	defer d.quarantineMutex.Unlock()
+
+	quarantine := d.quarantine
+	d.quarantine = nil
+	d.updateStatus(func(status *Status) {
+		status.Quarantined = false
+	})
+	return quarantine
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func numberedGroups(count int) map[string]*directory.Group {
+	groups := map[string]*directory.Group{}
+	for i := 1; i <= count; i++ {
+		id := "g" + strconv.Itoa(i)
+		groups[id] = &directory.Group{Id: id, Email: id + "@your.org"}
+	}
+	return groups
+}
+
+func TestQuarantineDroppedByLaterPublish(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10), thresholds: Thresholds{MaxGroupDrop: 20}}
+	d.publish(Data{Groups: numberedGroups(10)})
+
+	a.Nil(d.publishGuarded(Data{Groups: numberedGroups(1)}))
+	a.NotNil(d.Quarantine())
+	a.EqualValues(1, d.Quarantine().BaseGeneration)
+	a.True(d.Status().Quarantined)
+
+	// A clean sync publishes and drops the quarantine
+	snapshot := d.publishGuarded(Data{Groups: numberedGroups(11)})
+	a.NotNil(snapshot)
+	a.Nil(d.Quarantine())
+	a.False(d.Status().Quarantined)
+
+	a.Equal(ErrNoQuarantine, d.ApplyQuarantine())
+	a.Equal(snapshot, d.Snapshot())
+	a.Len(d.Snapshot().Groups, 11)
+}
+
+func TestApplyQuarantine(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10), thresholds: Thresholds{MaxGroupDrop: 20}}
+	d.publish(Data{Groups: numberedGroups(10)})
+
+	// A quarantine compared against an older snapshot is never published
+	a.Nil(d.publishGuarded(Data{Groups: numberedGroups(1)}))
+	d.publish(Data{Groups: numberedGroups(12)})
+	a.Equal(ErrQuarantineOutdated, d.ApplyQuarantine())
+	a.Nil(d.Quarantine())
+	a.EqualValues(2, d.Snapshot().Generation)
+	a.Len(d.Snapshot().Groups, 12)
+
+	a.Nil(d.publishGuarded(Data{Groups: numberedGroups(2)}))
+	a.Nil(d.ApplyQuarantine())
+	a.Nil(d.Quarantine())
+	a.False(d.Status().Quarantined)
+	a.EqualValues(3, d.Snapshot().Generation)
+	a.Len(d.Snapshot().Groups, 2)
+}
@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSuccess     = "success"
+	OutcomeFailed      = "failed"
+	OutcomeCancelled   = "cancelled"
+	OutcomeQuarantined = "quarantined"
+
+	KindFull        = "full"
+	KindIncremental = "incremental"
+	KindChange      = "change"
+)
+
+type SyncRun struct {
+	Started     time.Time `json:"started"`
+	Finished    time.Time `json:"finished"`
+	Duration    Duration  `json:"duration"`
+	Kind        string    `json:"kind"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	Generation  uint64    `json:"generation"`
+	Groups      int       `json:"groups"`
+	Members     int       `json:"members"`
+	Memberships int       `json:"memberships"`
+	ApiCalls    int64     `json:"api_calls"`
+}
+
+// failed records the error, a cancelled context makes the run cancelled.
+func (r *SyncRun) failed(ctx context.Context, err error) {
+	r.Error = err.Error()
+	r.Outcome = OutcomeFailed
+	if ctx.Err() != nil {
+		r.Outcome = OutcomeCancelled
+	}
+}
+
+// succeeded records the published snapshot.
+func (r *SyncRun) succeeded(snapshot *Snapshot) {
+	r.Outcome = OutcomeSuccess
+	r.Generation = snapshot.Generation
+	r.Groups = len(snapshot.Groups)
+	r.Members = len(snapshot.MemberIdToGroupIds)
+	r.Memberships = snapshot.KnownMembers()
+}
+
+type Health struct {
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastFailure         time.Time `json:"last_failure"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+type History struct {
+	Health Health     `json:"health"`
+	Runs   []*SyncRun `json:"runs"`
+}
+
+// history keeps the last sync runs in a ring buffer.
+type history struct {
+	mutex  sync.Mutex
+	runs   []*SyncRun
+	next   int
+	health Health
+}
+
+func newHistory(size int) *history {
+	if size < 1 {
+		size = 1
+	}
+	return &history{
+		runs:   make([]*SyncRun, 0, size),
+		health: Health{Healthy: true},
+	}
+}
+
+func (h *history) add(run *SyncRun) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.runs) < cap(h.runs) {
+		h.runs = append(h.runs, run)
+	} else {
+		h.runs[h.next] = run
+	}
+	h.next = (h.next + 1) % cap(h.runs)
+
+	switch run.Outcome {
+	case OutcomeSuccess:
+		h.health.ConsecutiveFailures = 0
+		h.health.LastSuccess = run.Finished
+	case OutcomeFailed, OutcomeQuarantined:
+		h.health.ConsecutiveFailures++
+		h.health.LastFailure = run.Finished
+		h.health.LastError = run.Error
+	}
+	h.health.Healthy = h.health.ConsecutiveFailures == 0
+}
+
+// get returns the health summary and the runs, newest first.
+func (h *history) get() *History {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	runs := make([]*SyncRun, 0, len(h.runs))
+	for i := 1; i <= len(h.runs); i++ {
+		index := (h.next - i + len(h.runs)) % len(h.runs)
+		runs = append(runs, h.runs[index])
+	}
+	return &History{
+		Health: h.health,
+		Runs:   runs,
+	}
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google"
+	"github.com/fabzo/gcloud-directory-service/sync/google/googletest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/admin/directory/v1"
+)
+
+// waitFor polls the condition until it is met or a second has passed.
+func waitFor(t *testing.T, condition func() bool, message string) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting until %v", message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTriggerAndCancelSync(t *testing.T) {
+	a := assert.New(t)
+
+	server := googletest.NewServer()
+	defer server.Close()
+	server.SetGroup(&admin.Group{Id: "g1", Email: "g1@your.org"},
+		&admin.Member{Id: "u1", Email: "u1@your.org", Type: "USER"})
+
+	// Once blocking, group list requests wait until they are released
+	blocking := make(chan struct{})
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	server.Hook = func(r *http.Request) {
+		select {
+		case <-blocking:
+		default:
+			return
+		}
+		if r.URL.Path == "/admin/directory/v1/groups" {
+			started <- struct{}{}
+			<-release
+		}
+	}
+
+	client, err := google.NewWithHttpClient(server.Client(), "customer", "", google.Options{})
+	a.NoError(err)
+	d := &dirSync{
+		googleClient: client,
+		syncInterval: 60,
+		trigger:      make(chan struct{}, 1),
+		syncStopped:  make(chan struct{}),
+		history:      newHistory(10),
+		changes:      newChangeLog(10),
+	}
+	a.False(d.CancelSync(), "no sync is running")
+
+	ctx, stop := context.WithCancel(context.Background())
+	d.RunSyncLoop(ctx)
+	waitFor(t, func() bool { return len(d.History().Runs) == 1 }, "the first sync finished")
+	a.Equal(OutcomeSuccess, d.History().Runs[0].Outcome)
+
+	close(blocking)
+	a.True(d.TriggerSync())
+	<-started
+	a.True(d.TriggerSync())
+	a.False(d.TriggerSync(), "triggers are coalesced while one is pending")
+
+	a.True(d.CancelSync())
+	waitFor(t, func() bool { return len(d.History().Runs) == 2 }, "the cancelled sync finished")
+	a.Equal(OutcomeCancelled, d.History().Runs[0].Outcome)
+	a.EqualValues(1, d.Snapshot().Generation)
+
+	// The pending trigger starts another sync
+	<-started
+	close(release)
+	waitFor(t, func() bool { return len(d.History().Runs) == 3 }, "the triggered sync finished")
+	a.Equal(OutcomeSuccess, d.History().Runs[0].Outcome)
+	a.EqualValues(2, d.Snapshot().Generation)
+
+	stop()
+	select {
+	case <-d.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Sync loop did not stop")
+	}
+	a.False(d.CancelSync())
+}
@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
@@ -10,9 +11,7 @@ import (
 )
 
 type mockSync struct {
-	groups             map[string]*directory.Group
-	memberIdToGroupIds map[string][]string
-	emailToMember      map[string]directory.MemberType
+	snapshot *Snapshot
 }
 
 func Mock(storageLocation string) (DirSync, error) {
@@ -20,9 +19,15 @@ func Mock(storageLocation string) (DirSync, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &mockSync{groups: groups,
-		emailToMember:      directory.ToEmailMemberMapping(groups),
-		memberIdToGroupIds: directory.ToMemberIdGroupIdsMapping(groups)}, nil
+
+	data := Data{Groups: groups}
+	if fileInfo, err := os.Stat(storageLocation); err == nil && fileInfo.IsDir() {
+		err = data.readOptionalFiles(storageLocation)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &mockSync{snapshot: NewSnapshot(1, data, directory.DefaultMaxNestingDepth)}, nil
 }
 
 func getGroupsFromDisk(location string) (map[string]*directory.Group, error) {
@@ -53,27 +58,76 @@ func getGroupsFromDisk(location string) (map[string]*directory.Group, error) {
 	return groups, nil
 }
 
-func (m *mockSync) RunSyncLoop() {
+func (m *mockSync) RunSyncLoop(ctx context.Context) {
+}
+
+func (m *mockSync) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (m *mockSync) Flush() error {
+	return nil
+}
+
+func (m *mockSync) TriggerSync() bool {
+	return false
+}
+
+func (m *mockSync) CancelSync() bool {
+	return false
 }
 
 func (m *mockSync) Status() *Status {
-	knownGroups := len(m.groups)
-	userCounter := 0
-	for _, group := range m.groups {
-		userCounter += len(group.Members)
+	return &Status{
+		KnownGroups: len(m.snapshot.Groups),
+		KnownUsers:  m.snapshot.KnownMembers(),
+		Generation:  m.snapshot.Generation,
+		Hash:        m.snapshot.Hash,
 	}
-	knownUsers := userCounter
-	return &Status{KnownGroups: knownGroups, KnownUsers: knownUsers}
+}
+
+func (m *mockSync) History() *History {
+	return &History{Health: Health{Healthy: true}, Runs: []*SyncRun{}}
+}
+
+// Subscribe never notifies, as the mock never publishes another snapshot.
+func (m *mockSync) Subscribe() (<-chan struct{}, func()) {
+	return make(chan struct{}), func() {}
+}
+
+func (m *mockSync) Changes(since uint64) (*ChangeFeed, *ResyncRequired) {
+	if since != m.snapshot.Generation {
+		return nil, &ResyncRequired{ResyncRequired: true, Since: since, Oldest: m.snapshot.Generation, Generation: m.snapshot.Generation}
+	}
+	return &ChangeFeed{Since: since, Generation: m.snapshot.Generation, Changes: []*Change{}}, nil
+}
+
+func (m *mockSync) Quarantine() *Quarantine {
+	return nil
+}
+
+func (m *mockSync) ApplyQuarantine() error {
+	return ErrNoQuarantine
+}
+
+func (m *mockSync) DiscardQuarantine() bool {
+	return false
+}
+
+func (m *mockSync) Snapshot() *Snapshot {
+	return m.snapshot
 }
 
 func (m *mockSync) Directory() map[string]*directory.Group {
-	return m.groups
+	return m.snapshot.Groups
 }
 
 func (m *mockSync) MemberIdToGroupIdsMapping() map[string][]string {
-	return m.memberIdToGroupIds
+	return m.snapshot.MemberIdToGroupIds
 }
 
 func (m *mockSync) EmailToMemberMapping() map[string]directory.MemberType {
-	return m.emailToMember
+	return m.snapshot.EmailToMember
 }
@@ -0,0 +1,261 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+// Data is the synced content of the directory.
+type Data struct {
+	Groups     map[string]*directory.Group   `json:"groups"`
+	Users      map[string]*directory.User    `json:"users,omitempty"`
+	OrgUnits   map[string]*directory.OrgUnit `json:"org_units,omitempty"`
+	AdminRoles *directory.AdminRoles         `json:"admin_roles,omitempty"`
+	Domains    map[string]*directory.Domain  `json:"domains,omitempty"`
+}
+
+// Snapshot is a consistent view of the directory together with all indexes
+// derived from it. A snapshot is never modified after it has been published,
+// a sync always publishes a new one instead.
+type Snapshot struct {
+	Generation uint64
+	Hash       string
+	Created    time.Time
+
+	Data
+
+	MemberIdToGroupIds map[string][]string
+	EmailToMember      map[string]directory.MemberType
+	Emails             *directory.EmailIndex
+	OrgUnitTree        *directory.OrgUnitNode
+	Closure            *directory.Closure
+	Encoded            *EncodedResponses
+
+	customerMemberIds []string
+	maxNestingDepth   int
+}
+
+var emptySnapshot = NewSnapshot(0, Data{}, 0)
+
+// NewSnapshot builds the snapshot of the data. Nested group memberships are
+// resolved up to maxNestingDepth, 0 disables the limit.
+func NewSnapshot(generation uint64, data Data, maxNestingDepth int) *Snapshot {
+	snapshot := &Snapshot{
+		Generation:         generation,
+		Hash:               contentHash(data),
+		Created:            time.Now(),
+		Data:               data,
+		MemberIdToGroupIds: directory.ToMemberIdGroupIdsMapping(data.Groups),
+		EmailToMember:      directory.ToEmailMemberMapping(data.Groups),
+		Emails:             directory.NewEmailIndex(data.Groups, data.Users, data.Domains),
+		OrgUnitTree:        directory.ToOrgUnitTree(data.OrgUnits),
+		Closure:            directory.ToClosure(data.Groups, maxNestingDepth),
+		customerMemberIds:  toCustomerMemberIds(data.Groups),
+		maxNestingDepth:    maxNestingDepth,
+	}
+	snapshot.Encoded = encodeResponses(snapshot)
+	return snapshot
+}
+
+// withGroups returns a copy of the data of the snapshot with other groups.
+func (s *Snapshot) withGroups(groups map[string]*directory.Group) Data {
+	data := s.Data
+	data.Groups = groups
+	return data
+}
+
+// KnownMembers returns the number of memberships in the snapshot.
+func (s *Snapshot) KnownMembers() int {
+	counter := 0
+	for _, group := range s.Groups {
+		counter += len(group.Members)
+	}
+	return counter
+}
+
+// Group returns the group with the given id, email address or alias. Email
+// addresses are resolved in their canonical form.
+func (s *Snapshot) Group(idOrEmail string) (*directory.Group, bool) {
+	if group, ok := s.Groups[idOrEmail]; ok {
+		return group, true
+	}
+	if member, ok := s.Emails.Member(idOrEmail); ok && member.Type == directory.GroupType {
+		group, ok := s.Groups[member.Id]
+		return group, ok
+	}
+	return nil, false
+}
+
+// GroupMembers returns the members of the group sorted by id. With transitive
+// the members of nested groups are included with their nesting depth.
+func (s *Snapshot) GroupMembers(groupId string, transitive bool) []directory.EffectiveMember {
+	if transitive {
+		members := s.Closure.Members[groupId]
+		if members == nil {
+			members = []directory.EffectiveMember{}
+		}
+		return members
+	}
+
+	members := make([]directory.EffectiveMember, 0)
+	group, ok := s.Groups[groupId]
+	if !ok {
+		return members
+	}
+	for id, member := range group.Members {
+		members = append(members, directory.EffectiveMember{Id: id, Email: member.Email, Type: member.Type, Depth: 1})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Id < members[j].Id
+	})
+	return members
+}
+
+// Member returns the group, user or other member with the given id or email
+// address. Email addresses are resolved in their canonical form.
+func (s *Snapshot) Member(idOrEmail string) (*directory.Member, bool) {
+	id := idOrEmail
+	if member, ok := s.Emails.Member(idOrEmail); ok {
+		id = member.Id
+	}
+	if group, ok := s.Groups[id]; ok {
+		return &directory.Member{Id: id, Email: group.Email, Type: directory.GroupType}, true
+	}
+	if user, ok := s.Users[id]; ok {
+		return &directory.Member{Id: id, Email: user.PrimaryEmail, Type: directory.UserType}, true
+	}
+	for _, groupId := range s.MemberIdToGroupIds[id] {
+		if member, ok := s.Groups[groupId].Members[id]; ok {
+			return &directory.Member{Id: id, Email: member.Email, Type: member.Type}, true
+		}
+	}
+	return nil, false
+}
+
+// MemberGroup is the membership of a member in a group. Role and status are
+// only known for direct memberships.
+type MemberGroup struct {
+	GroupId string `json:"group_id"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Role    string `json:"role,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Depth   int    `json:"depth"`
+}
+
+// MemberGroups returns the groups the member is part of, sorted by depth and
+// group id. With transitive the groups the member is part of through nested
+// groups are included.
+func (s *Snapshot) MemberGroups(memberId string, transitive bool) []*MemberGroup {
+	memberGroups := make([]*MemberGroup, 0)
+	if transitive {
+		for _, membership := range s.Closure.Memberships[memberId] {
+			memberGroups = append(memberGroups, s.memberGroup(membership.GroupId, memberId, membership.Depth))
+		}
+		return memberGroups
+	}
+
+	for _, groupId := range s.MemberIdToGroupIds[memberId] {
+		memberGroups = append(memberGroups, s.memberGroup(groupId, memberId, 1))
+	}
+	sort.Slice(memberGroups, func(i, j int) bool {
+		return memberGroups[i].GroupId < memberGroups[j].GroupId
+	})
+	return memberGroups
+}
+
+func (s *Snapshot) memberGroup(groupId string, memberId string, depth int) *MemberGroup {
+	group := s.Groups[groupId]
+	memberGroup := &MemberGroup{
+		GroupId: groupId,
+		Email:   group.Email,
+		Name:    group.Name,
+		Depth:   depth,
+	}
+	if member, ok := group.Members[memberId]; ok && depth == 1 {
+		memberGroup.Role = member.Role
+		memberGroup.Status = member.Status
+	}
+	return memberGroup
+}
+
+// GroupOwners returns the members of the group with the OWNER or MANAGER
+// role, owners first and each sorted by email.
+func (s *Snapshot) GroupOwners(groupId string) []*directory.Member {
+	owners := make([]*directory.Member, 0)
+	group, ok := s.Groups[groupId]
+	if !ok {
+		return owners
+	}
+	for _, member := range group.Members {
+		if member.Role == directory.OwnerRole || member.Role == directory.ManagerRole {
+			owners = append(owners, member)
+		}
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Role != owners[j].Role {
+			return owners[i].Role == directory.OwnerRole
+		}
+		return owners[i].Email < owners[j].Email
+	})
+	return owners
+}
+
+// User returns the user with the given id or email address. Email addresses
+// are resolved in their canonical form.
+func (s *Snapshot) User(idOrEmail string) (*directory.User, bool) {
+	if user, ok := s.Users[idOrEmail]; ok {
+		return user, true
+	}
+	if id, ok := s.Emails.UserId(idOrEmail); ok {
+		return s.Users[id], true
+	}
+	return nil, false
+}
+
+// OrgUnit returns the org unit with the given path. The root org unit is
+// always known.
+func (s *Snapshot) OrgUnit(path string) (*directory.OrgUnit, bool) {
+	if path == directory.RootOrgUnitPath {
+		return s.OrgUnitTree.OrgUnit, true
+	}
+	orgUnit, ok := s.OrgUnits[path]
+	return orgUnit, ok
+}
+
+// UsersInOrgUnit returns the users of the org unit, sorted by their primary
+// email. With recursive the users of all sub org units are included.
+func (s *Snapshot) UsersInOrgUnit(path string, recursive bool) []*directory.User {
+	users := make([]*directory.User, 0)
+	for _, user := range s.Users {
+		if directory.IsInOrgUnit(user.OrgUnitPath, path, recursive) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].PrimaryEmail < users[j].PrimaryEmail
+	})
+	return users
+}
+
+// UserAdminRoles returns the admin roles assigned to the user with the given
+// id.
+func (s *Snapshot) UserAdminRoles(userId string) []*directory.UserAdminRole {
+	return s.AdminRoles.ToUserAdminRoles(userId, s.OrgUnits)
+}
+
+// contentHash hashes the JSON encoding of the data. Map keys are encoded in
+// sorted order, so equal content always results in the same hash.
+func contentHash(data Data) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
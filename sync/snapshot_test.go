@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrgUnitQueries(t *testing.T) {
+	a := assert.New(t)
+
+	snapshot := NewSnapshot(1, Data{
+		OrgUnits: map[string]*directory.OrgUnit{
+			"/Engineering":         {Path: "/Engineering", ParentPath: "/"},
+			"/Engineering/Backend": {Path: "/Engineering/Backend", ParentPath: "/Engineering"},
+			"/EngineeringOps":      {Path: "/EngineeringOps", ParentPath: "/"},
+		},
+		Users: map[string]*directory.User{
+			"u1": {Id: "u1", PrimaryEmail: "b@your.org", OrgUnitPath: "/Engineering"},
+			"u2": {Id: "u2", PrimaryEmail: "a@your.org", OrgUnitPath: "/Engineering/Backend"},
+			"u3": {Id: "u3", PrimaryEmail: "c@your.org", OrgUnitPath: "/EngineeringOps"},
+		},
+	}, 0)
+
+	a.Equal("/", snapshot.OrgUnitTree.Path)
+	a.Len(snapshot.OrgUnitTree.Children, 2)
+	a.Equal("/Engineering/Backend", snapshot.OrgUnitTree.Children[0].Children[0].Path)
+
+	a.Len(snapshot.UsersInOrgUnit("/Engineering", false), 1)
+	users := snapshot.UsersInOrgUnit("/Engineering", true)
+	a.Len(users, 2)
+	a.Equal("a@your.org", users[0].PrimaryEmail)
+	a.Len(snapshot.UsersInOrgUnit("/", true), 3)
+	a.Empty(snapshot.UsersInOrgUnit("/", false))
+}
+
+func TestUserAdminRoles(t *testing.T) {
+	a := assert.New(t)
+
+	snapshot := NewSnapshot(1, Data{
+		OrgUnits: map[string]*directory.OrgUnit{
+			"/Engineering": {Id: "id:ou1", Path: "/Engineering", ParentPath: "/"},
+		},
+		AdminRoles: &directory.AdminRoles{
+			Roles: map[string]*directory.AdminRole{
+				"1": {Id: "1", Name: "_SEED_ADMIN_ROLE", IsSuperAdminRole: true},
+				"2": {Id: "2", Name: "_GROUPS_ADMIN_ROLE"},
+			},
+			Assignments: map[string]*directory.RoleAssignment{
+				"a1": {Id: "a1", RoleId: "2", AssignedTo: "u1", ScopeType: directory.OrgUnitScope, OrgUnitId: "ou1"},
+				"a2": {Id: "a2", RoleId: "1", AssignedTo: "u1", ScopeType: directory.CustomerScope},
+				"a3": {Id: "a3", RoleId: "1", AssignedTo: "u2", ScopeType: directory.CustomerScope},
+			},
+		},
+	}, 0)
+
+	roles := snapshot.UserAdminRoles("u1")
+	a.Len(roles, 2)
+	a.True(roles[0].Role.IsSuperAdminRole)
+	a.Equal("/Engineering", roles[1].OrgUnitPath)
+	a.Empty(snapshot.UserAdminRoles("u3"))
+	a.Empty(NewSnapshot(1, Data{}, 0).UserAdminRoles("u1"))
+}
+
+func TestUserByEmail(t *testing.T) {
+	a := assert.New(t)
+
+	snapshot := NewSnapshot(1, Data{
+		Users: map[string]*directory.User{
+			"u1": {Id: "u1", PrimaryEmail: "Jane.Doe@your.org", Aliases: []string{"jane@your.org"}},
+		},
+		Domains: map[string]*directory.Domain{
+			"your.org": {Name: "your.org", IsPrimary: true, Aliases: []*directory.DomainAlias{{Name: "alias-your.org"}}},
+		},
+	}, 0)
+
+	user, ok := snapshot.User("u1")
+	a.True(ok)
+	a.Equal("u1", user.Id)
+	user, ok = snapshot.User("JANE@alias-your.org")
+	a.True(ok)
+	a.Equal("u1", user.Id)
+	_, ok = snapshot.User("jane@other.org")
+	a.False(ok)
+}
+
+func TestGroupMembers(t *testing.T) {
+	a := assert.New(t)
+
+	snapshot := NewSnapshot(1, Data{Groups: map[string]*directory.Group{
+		"g1": {Id: "g1", Members: map[string]*directory.Member{"u1": {Id: "u1", Email: "u1@your.org", Type: directory.UserType}}},
+		"g2": {Id: "g2", Members: map[string]*directory.Member{"g1": {Id: "g1", Email: "g1@your.org", Type: directory.GroupType}}},
+	}}, 0)
+
+	members := snapshot.GroupMembers("g2", true)
+	a.Len(members, 2)
+	a.Equal(directory.EffectiveMember{Id: "g1", Email: "g1@your.org", Type: directory.GroupType, Depth: 1}, members[0])
+	a.Equal(directory.EffectiveMember{Id: "u1", Email: "u1@your.org", Type: directory.UserType, Depth: 2}, members[1])
+	a.Len(snapshot.GroupMembers("g2", false), 1)
+	a.Empty(snapshot.GroupMembers("g3", true))
+}
+
+func TestMemberGroupsAndOwners(t *testing.T) {
+	a := assert.New(t)
+
+	groups := map[string]*directory.Group{
+		"g1": {Id: "g1", Email: "g1@your.org", Members: map[string]*directory.Member{
+			"u1": {Id: "u1", Email: "b@your.org", Type: directory.UserType, Role: directory.ManagerRole, Status: "ACTIVE"},
+			"u2": {Id: "u2", Email: "c@your.org", Type: directory.UserType, Role: directory.OwnerRole},
+			"u3": {Id: "u3", Email: "a@your.org", Type: directory.UserType, Role: directory.MemberRole},
+		}},
+		"g2": {Id: "g2", Email: "g2@your.org", Aliases: []string{"team@your.org"}, Members: map[string]*directory.Member{
+			"g1": {Id: "g1", Email: "g1@your.org", Type: directory.GroupType, Role: directory.MemberRole},
+		}},
+	}
+	snapshot := NewSnapshot(1, Data{Groups: groups}, 0)
+
+	group, ok := snapshot.Group("TEAM@your.org")
+	a.True(ok)
+	a.Equal("g2", group.Id)
+	_, ok = snapshot.Group("b@your.org")
+	a.False(ok)
+
+	member, ok := snapshot.Member("B@your.org")
+	a.True(ok)
+	a.Equal(&directory.Member{Id: "u1", Email: "b@your.org", Type: directory.UserType}, member)
+
+	memberGroups := snapshot.MemberGroups("u1", false)
+	a.Len(memberGroups, 1)
+	a.Equal(directory.ManagerRole, memberGroups[0].Role)
+	memberGroups = snapshot.MemberGroups("u1", true)
+	a.Len(memberGroups, 2)
+	a.Equal(&MemberGroup{GroupId: "g2", Email: "g2@your.org", Depth: 2}, memberGroups[1])
+
+	owners := snapshot.GroupOwners("g1")
+	a.Len(owners, 2)
+	a.Equal("u2", owners[0].Id)
+	a.Equal("u1", owners[1].Id)
+	a.Empty(snapshot.GroupOwners("g2"))
+}
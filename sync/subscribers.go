@@ -0,0 +1,42 @@
+package sync
+
+import (
+	"sync"
+)
+
+// subscribers are notified about every published snapshot. Notifications are
+// coalesced: every subscriber has room for a single pending notification, so
+// publishing never waits for slow subscribers.
+type subscribers struct {
+	mutex    sync.Mutex
+	channels map[chan struct{}]struct{}
+}
+
+func (s *subscribers) subscribe() (<-chan struct{}, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.channels == nil {
+		s.channels = map[chan struct{}]struct{}{}
+	}
+	channel := make(chan struct{}, 1)
+	s.channels[channel] = struct{}{}
+
+	return channel, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.channels, channel)
+	}
+}
+
+func (s *subscribers) notify() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for channel := range s.channels {
+		select {
+		case channel <- struct{}{}:
+		default:
+		}
+	}
+}
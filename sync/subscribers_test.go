@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10)}
+	notifications, unsubscribe := d.Subscribe()
+
+	// Notifications are coalesced, publishing never waits for the subscriber
+	d.publish(Data{})
+	d.publish(Data{})
+	select {
+	case <-notifications:
+	default:
+		a.Fail("expected a notification")
+	}
+	select {
+	case <-notifications:
+		a.Fail("expected a single notification")
+	default:
+	}
+
+	unsubscribe()
+	d.publish(Data{})
+	select {
+	case <-notifications:
+		a.Fail("expected no notification after unsubscribe")
+	default:
+	}
+}
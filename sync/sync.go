@@ -1,10 +1,14 @@
 package sync
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fabzo/gcloud-directory-service/sync/google"
@@ -13,31 +17,134 @@ import (
 )
 
 type DirSync interface {
-	RunSyncLoop()
+	RunSyncLoop(ctx context.Context)
+	Done() <-chan struct{}
+	Flush() error
+	TriggerSync() bool
+	CancelSync() bool
 	Status() *Status
+	History() *History
+	Changes(since uint64) (*ChangeFeed, *ResyncRequired)
+	// Subscribe returns a channel that receives a notification after snapshots
+	// are published. Notifications are coalesced, the changes have to be read
+	// from Changes. The returned function ends the subscription.
+	Subscribe() (<-chan struct{}, func())
+	Snapshot() *Snapshot
+	Quarantine() *Quarantine
+	ApplyQuarantine() error
+	DiscardQuarantine() bool
 	Directory() map[string]*directory.Group
 	MemberIdToGroupIdsMapping() map[string][]string
 	EmailToMemberMapping() map[string]directory.MemberType
 }
 
+type Config struct {
+	ServiceAccountFile string
+	Subject            string
+	CustomerId         string
+	Domain             string
+	SyncInterval       int
+	SyncWorkers        int
+	StorageLocation    string
+
+	// Incremental only refetches the members of groups whose ETag changed.
+	// A full sync is still executed every FullSyncInterval minutes.
+	Incremental      bool
+	FullSyncInterval int
+
+	// ChangeSync reads the groups activity feed of the admin reports API every
+	// ChangeSyncInterval seconds and refreshes the affected groups in between
+	// the regular syncs.
+	ChangeSync         bool
+	ChangeSyncInterval int
+
+	// QPS limits the API calls per second. Calls failing with a retryable error
+	// are retried up to MaxRetries times.
+	QPS        float64
+	MaxRetries int
+
+	// Thresholds protect against publishing a directory that dropped
+	// suspiciously many groups, members or memberships.
+	Thresholds Thresholds
+
+	// HistorySize is the number of sync runs kept for /api/status/history.
+	HistorySize int
+	// ChangeLogSize is the number of generations whose changes are kept for
+	// /api/changes.
+	ChangeLogSize int
+
+	// SyncUsers retrieves the users of the customer alongside the groups.
+	SyncUsers bool
+	// SyncOrgUnits retrieves the org unit hierarchy of the customer.
+	SyncOrgUnits bool
+	// SyncGroupSettings retrieves the settings of every group alongside its
+	// members.
+	SyncGroupSettings bool
+	// SyncAdminRoles retrieves the admin roles and their assignments.
+	SyncAdminRoles bool
+	// SyncDomains retrieves the domains and domain aliases of the customer,
+	// which are used to resolve email addresses in alias domains.
+	SyncDomains bool
+
+	// MaxNestingDepth limits the number of nested groups followed when
+	// resolving transitive memberships. 0 disables the limit.
+	MaxNestingDepth int
+}
+
 type dirSync struct {
 	serviceAccountFile string
 	subject            string
 	customerId         string
 	domain             string
 	syncInterval       int
+	syncWorkers        int
 	storageLocation    string
+	incremental        bool
+	fullSyncInterval   int
+	lastFullSync       time.Time
+	changeSync         bool
+	changeSyncInterval int
+	checkpoint         checkpoint
+	qps                float64
+	maxRetries         int
+	thresholds         Thresholds
+	syncUsers          bool
+	syncOrgUnits       bool
+	syncGroupSettings  bool
+	syncAdminRoles     bool
+	syncDomains        bool
+	maxNestingDepth    int
 
 	syncRunningMutex sync.Mutex
 	syncRunning      bool
+	syncStopped      chan struct{}
+
+	trigger       chan struct{}
+	cancelMutex   sync.Mutex
+	cancelCurrent context.CancelFunc
 
 	googleClient *google.Client
 
-	groups             map[string]*directory.Group
-	memberIdToGroupIds map[string][]string
-	emailToMember      map[string]directory.MemberType
+	// snapshot holds the current *Snapshot. It is replaced as a whole, so
+	// readers always see the result of a single sync.
+	snapshot     atomic.Value
+	publishMutex sync.Mutex
+	generation   uint64
+
+	persistMutex        sync.Mutex
+	persistedGeneration uint64
+
+	quarantineMutex sync.Mutex
+	quarantine      *Quarantine
 
-	status *Status
+	lastFetched map[string]time.Time
+	failures    map[string]*GroupFailure
+
+	statusMutex sync.RWMutex
+	status      Status
+	history     *history
+	changes     *changeLog
+	subscribers subscribers
 }
 
 type Duration struct {
@@ -49,149 +156,566 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 }
 
 type Status struct {
-	LastSync         time.Time `json:"last_sync"`
-	LastSyncDuration Duration  `json:"last_sync_duration"`
-	NextSync         time.Time `json:"next_sync"`
-	KnownGroups      int       `json:"known_groups"`
-	KnownUsers       int       `json:"known_users"`
-	SyncInProgress   bool      `json:"sync_in_progress"`
+	LastSync         time.Time       `json:"last_sync"`
+	LastSyncDuration Duration        `json:"last_sync_duration"`
+	NextSync         time.Time       `json:"next_sync"`
+	KnownGroups      int             `json:"known_groups"`
+	KnownUsers       int             `json:"known_users"`
+	SyncInProgress   bool            `json:"sync_in_progress"`
+	SyncWorkers      int             `json:"sync_workers"`
+	LastFullSync     time.Time       `json:"last_full_sync"`
+	ReusedGroups     int             `json:"reused_groups"`
+	RefetchedGroups  int             `json:"refetched_groups"`
+	LastChangeSync   time.Time       `json:"last_change_sync"`
+	ChangeCheckpoint time.Time       `json:"change_checkpoint"`
+	ChangedGroups    int             `json:"changed_groups"`
+	ApiCalls         int64           `json:"api_calls"`
+	Retries          int64           `json:"retries"`
+	ThrottledTime    Duration        `json:"throttled_time"`
+	BackoffTime      Duration        `json:"backoff_time"`
+	FailingGroups    []*GroupFailure `json:"failing_groups,omitempty"`
+	Generation       uint64          `json:"generation"`
+	Hash             string          `json:"hash"`
+	Quarantined      bool            `json:"quarantined"`
+	SyncedUsers      int             `json:"synced_users"`
+	SyncedOrgUnits   int             `json:"synced_org_units"`
+	SyncedAdminRoles int             `json:"synced_admin_roles"`
+	SyncedDomains    int             `json:"synced_domains"`
+	GroupCycles      []string        `json:"group_cycles,omitempty"`
 }
 
-func New(serviceAccountFile string, subject string, customerId string, domain string, syncInterval int, storageLocation string) (DirSync, error) {
+func New(config Config) (DirSync, error) {
 
-	if serviceAccountFile == "" {
+	if config.ServiceAccountFile == "" {
 		return nil, fmt.Errorf("service account location cannot be empty")
 	}
-	if customerId == "" {
+	if config.CustomerId == "" {
 		return nil, fmt.Errorf("customer id cannot be empty")
 	}
-	if syncInterval < 5 {
+	if config.SyncInterval < 5 {
 		return nil, fmt.Errorf("sync interval cannot be lower than 5 minutes")
 	}
+	if config.SyncWorkers < 1 {
+		return nil, fmt.Errorf("sync workers cannot be lower than 1")
+	}
+	if config.Incremental && config.FullSyncInterval < config.SyncInterval {
+		return nil, fmt.Errorf("full sync interval cannot be lower than the sync interval")
+	}
+	if config.ChangeSync && config.ChangeSyncInterval < 10 {
+		return nil, fmt.Errorf("change sync interval cannot be lower than 10 seconds")
+	}
+	if config.MaxRetries < 0 {
+		return nil, fmt.Errorf("max retries cannot be negative")
+	}
+	if config.HistorySize < 1 {
+		return nil, fmt.Errorf("history size cannot be lower than 1")
+	}
+	if config.ChangeLogSize < 1 {
+		return nil, fmt.Errorf("change log size cannot be lower than 1")
+	}
 
 	dirSync := &dirSync{
-		serviceAccountFile: serviceAccountFile,
-		subject:            subject,
-		customerId:         customerId,
-		domain:             domain,
-		syncInterval:       syncInterval,
-		storageLocation:    storageLocation,
-		status:             &Status{},
+		serviceAccountFile: config.ServiceAccountFile,
+		subject:            config.Subject,
+		customerId:         config.CustomerId,
+		domain:             config.Domain,
+		syncInterval:       config.SyncInterval,
+		syncWorkers:        config.SyncWorkers,
+		storageLocation:    config.StorageLocation,
+		incremental:        config.Incremental,
+		fullSyncInterval:   config.FullSyncInterval,
+		changeSync:         config.ChangeSync,
+		changeSyncInterval: config.ChangeSyncInterval,
+		qps:                config.QPS,
+		maxRetries:         config.MaxRetries,
+		thresholds:         config.Thresholds,
+		syncUsers:          config.SyncUsers,
+		syncOrgUnits:       config.SyncOrgUnits,
+		syncGroupSettings:  config.SyncGroupSettings,
+		syncAdminRoles:     config.SyncAdminRoles,
+		syncDomains:        config.SyncDomains,
+		maxNestingDepth:    config.MaxNestingDepth,
+		status:             Status{SyncWorkers: config.SyncWorkers},
 		syncRunning:        false,
+		trigger:            make(chan struct{}, 1),
+		syncStopped:        make(chan struct{}),
+		lastFetched:        map[string]time.Time{},
+		failures:           map[string]*GroupFailure{},
+		history:            newHistory(config.HistorySize),
+		changes:            newChangeLog(config.ChangeLogSize),
 	}
 
-	err := dirSync.restoreFromDisk(storageLocation)
+	err := dirSync.restoreFromDisk(config.StorageLocation)
 	if err != nil {
 		logrus.Warnf("Failed to restore directory from disk: %v", err)
 	}
 
+	if config.ChangeSync {
+		err = dirSync.restoreCheckpoint(config.StorageLocation)
+		if err != nil {
+			logrus.Warnf("Failed to restore change checkpoint from disk: %v", err)
+		}
+	}
+
 	return dirSync, nil
 }
 
-func (d *dirSync) RunSyncLoop() {
+func (d *dirSync) RunSyncLoop(ctx context.Context) {
 	d.syncRunningMutex.Lock()
 	defer d.syncRunningMutex.Unlock()
 	if !d.syncRunning {
 		d.syncRunning = true
-		go d.syncLoop()
+		go func() {
+			defer close(d.syncStopped)
+			d.syncLoop(ctx)
+		}()
 	}
 }
 
-func (d *dirSync) syncLoop() {
-	for true {
+// Done returns a channel that is closed once the sync loop stopped after its
+// context was cancelled. It is closed right away if the loop never ran.
+func (d *dirSync) Done() <-chan struct{} {
+	d.syncRunningMutex.Lock()
+	defer d.syncRunningMutex.Unlock()
+	if !d.syncRunning {
+		stopped := make(chan struct{})
+		close(stopped)
+		return stopped
+	}
+	return d.syncStopped
+}
 
-		if d.googleClient == nil {
-			serviceAccount, err := ioutil.ReadFile(d.serviceAccountFile)
-			if err != nil {
-				logrus.Errorf("Could not read service account file. Skipping current sync attempt. Error: %v", err)
-				goto skip
-			}
+// Flush writes the current snapshot to disk unless it has been persisted
+// already. It is used on shutdown to not lose a snapshot whose persistence
+// failed or has not happened yet.
+func (d *dirSync) Flush() error {
+	return d.persistToDisk(d.storageLocation, d.Snapshot())
+}
 
-			d.googleClient, err = google.New(serviceAccount, d.subject, d.customerId, d.domain)
-			if err != nil {
-				logrus.Errorf("Could not initiate google client. Skipping current sync attempt. Error: %v", err)
-				goto skip
+// TriggerSync requests an immediate sync. Triggers that arrive while another
+// trigger is still pending are coalesced into it, in which case false is
+// returned.
+func (d *dirSync) TriggerSync() bool {
+	select {
+	case d.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// CancelSync cancels the sync that is currently in progress. It returns false
+// if no sync is running.
+func (d *dirSync) CancelSync() bool {
+	d.cancelMutex.Lock()
+	defer d.cancelMutex.Unlock()
+	if d.cancelCurrent == nil {
+		return false
+	}
+	d.cancelCurrent()
+	d.cancelCurrent = nil
+	return true
+}
+
+func (d *dirSync) syncLoop(ctx context.Context) {
+	nextSync := time.Now()
+	triggered := false
+
+	for {
+		if d.ensureGoogleClient() {
+			if triggered || !time.Now().Before(nextSync) {
+				d.cancelable(ctx, d.executeSync)
+				nextSync = time.Now().Add(time.Duration(d.syncInterval) * time.Minute)
+			} else {
+				d.cancelable(ctx, d.executeChangeSync)
 			}
+		} else {
+			nextSync = time.Now().Add(time.Duration(d.syncInterval) * time.Minute)
 		}
+		triggered = false
+
+		timer := time.NewTimer(d.sleepDuration(nextSync))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logrus.Infof("Sync loop stopped")
+			return
+		case <-d.trigger:
+			timer.Stop()
+			triggered = true
+		case <-timer.C:
+		}
+	}
+}
+
+// cancelable runs the given sync function with a context that can be
+// cancelled through CancelSync.
+func (d *dirSync) cancelable(ctx context.Context, fn func(ctx context.Context)) {
+	syncCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		d.executeSync()
+	d.cancelMutex.Lock()
+	d.cancelCurrent = cancel
+	d.cancelMutex.Unlock()
 
-	skip:
-		time.Sleep(time.Duration(d.syncInterval) * time.Minute)
+	fn(syncCtx)
+
+	d.cancelMutex.Lock()
+	d.cancelCurrent = nil
+	d.cancelMutex.Unlock()
+}
+
+func (d *dirSync) ensureGoogleClient() bool {
+	if d.googleClient != nil {
+		return true
+	}
+
+	serviceAccount, err := ioutil.ReadFile(d.serviceAccountFile)
+	if err != nil {
+		logrus.Errorf("Could not read service account file. Skipping current sync attempt. Error: %v", err)
+		return false
+	}
+
+	d.googleClient, err = google.New(serviceAccount, d.subject, d.customerId, d.domain, google.Options{
+		Workers:       d.syncWorkers,
+		Reports:       d.changeSync,
+		QPS:           d.qps,
+		MaxRetries:    d.maxRetries,
+		Users:         d.syncUsers,
+		OrgUnits:      d.syncOrgUnits,
+		GroupSettings: d.syncGroupSettings,
+		AdminRoles:    d.syncAdminRoles,
+		Domains:       d.syncDomains,
+	})
+	if err != nil {
+		logrus.Errorf("Could not initiate google client. Skipping current sync attempt. Error: %v", err)
+		return false
 	}
+	return true
 }
 
-func (d *dirSync) executeSync() {
-	d.status.LastSync = time.Now()
-	d.status.SyncInProgress = true
+// sleepDuration returns the time until the next sync or, if change sync is
+// enabled and due earlier, until the next read of the activity feed.
+func (d *dirSync) sleepDuration(nextSync time.Time) time.Duration {
+	sleep := time.Until(nextSync)
+	if d.isChangeSyncEnabled() {
+		changeSleep := time.Duration(d.changeSyncInterval) * time.Second
+		if changeSleep < sleep {
+			sleep = changeSleep
+		}
+	}
+	if sleep < 0 {
+		return 0
+	}
+	return sleep
+}
 
-	groups, err := d.googleClient.Directory.RetrieveDirectory()
+func (d *dirSync) executeSync(ctx context.Context) {
+	started := time.Now()
+	d.updateStatus(func(status *Status) {
+		status.LastSync = started
+		status.SyncInProgress = true
+	})
+
+	callsBefore := d.googleClient.Scheduler.Stats()
+	current := d.Snapshot()
+	fullSync := d.isFullSyncDue(current)
+	var previous map[string]*directory.Group
+	run := &SyncRun{Started: started, Kind: KindFull}
+	if !fullSync {
+		previous = current.Groups
+		run.Kind = KindIncremental
+	}
+
+	groups, stats, err := d.googleClient.Directory.RetrieveDirectory(ctx, previous)
 	if err != nil {
-		logrus.Errorf("Failed to execute sync. Error: %v", err)
+		run.failed(ctx, err)
+		if ctx.Err() != nil {
+			logrus.Warnf("Sync was cancelled. Keeping the previous directory.")
+		} else {
+			logrus.Errorf("Failed to execute sync. Error: %v", err)
+		}
 	} else {
-		d.updateGroups(groups)
+		d.markFailed(current, groups, stats.Failed)
+		snapshot := d.publishGuarded(d.retrieveData(ctx, current, groups))
+		if snapshot == nil {
+			run.Outcome = OutcomeQuarantined
+			run.Error = "synced directory was quarantined"
+		} else {
+			run.succeeded(snapshot)
+			d.applyFailures(groups, stats.Failed, started)
+		}
 
-		err = d.persistToDisk(d.storageLocation)
-		if err != nil {
-			logrus.Warnf("Failed to persist directory to disk: %v", err)
+		if fullSync && snapshot != nil {
+			d.lastFullSync = started
+			d.advanceCheckpoint(started)
+		}
+		d.updateStatus(func(status *Status) {
+			if fullSync && snapshot != nil {
+				status.LastFullSync = started
+			}
+			status.ReusedGroups = stats.Reused
+			status.RefetchedGroups = stats.Refetched
+		})
+		logrus.Infof("Sync finished (full: %v). Reused %d and refetched %d groups, %d failed, %d deleted during sync",
+			fullSync, stats.Reused, stats.Refetched, len(stats.Failed), len(stats.Deleted))
+
+		if snapshot != nil {
+			err = d.persistToDisk(d.storageLocation, snapshot)
+			if err != nil {
+				logrus.Warnf("Failed to persist directory to disk: %v", err)
+			}
 		}
 	}
 
-	d.status.SyncInProgress = false
-	d.status.LastSyncDuration = Duration{time.Since(d.status.LastSync)}
-	d.status.NextSync = time.Now().Add(time.Duration(d.syncInterval) * time.Minute)
+	calls := d.googleClient.Scheduler.Stats().Sub(callsBefore)
+	run.ApiCalls = calls.Calls
+	run.Finished = time.Now()
+	run.Duration = Duration{run.Finished.Sub(started)}
+	d.history.add(run)
+
+	d.updateStatus(func(status *Status) {
+		status.ApiCalls = calls.Calls
+		status.Retries = calls.Retries
+		status.ThrottledTime = Duration{calls.ThrottledTime}
+		status.BackoffTime = Duration{calls.BackoffTime}
+
+		status.SyncInProgress = false
+		status.LastSyncDuration = run.Duration
+		status.NextSync = time.Now().Add(time.Duration(d.syncInterval) * time.Minute)
+	})
 }
 
-func (d *dirSync) updateStatusCounter(groups map[string]*directory.Group) {
-	d.status.KnownGroups = len(d.groups)
-	userCounter := 0
-	for _, group := range d.groups {
-		userCounter += len(group.Members)
+// isFullSyncDue reports whether the next sync has to fetch the members of all
+// groups. This is always the case without incremental mode, for the first sync
+// after a start and whenever the full sync interval has passed.
+func (d *dirSync) isFullSyncDue(current *Snapshot) bool {
+	if !d.incremental || current.Groups == nil || d.lastFullSync.IsZero() {
+		return true
+	}
+	return time.Since(d.lastFullSync) >= time.Duration(d.fullSyncInterval)*time.Minute
+}
+
+// retrieveData completes the synced groups with all other enabled data. Data
+// that cannot be retrieved is taken over from the current snapshot.
+func (d *dirSync) retrieveData(ctx context.Context, current *Snapshot, groups map[string]*directory.Group) Data {
+	data := Data{Groups: groups}
+
+	if d.syncUsers {
+		users, err := d.googleClient.Directory.RetrieveUsers(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to retrieve users. Keeping the previous users. Error: %v", err)
+			users = current.Users
+		}
+		data.Users = users
+	}
+
+	if d.syncOrgUnits {
+		orgUnits, err := d.googleClient.Directory.RetrieveOrgUnits(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to retrieve org units. Keeping the previous org units. Error: %v", err)
+			orgUnits = current.OrgUnits
+		}
+		data.OrgUnits = orgUnits
+	}
+
+	if d.syncAdminRoles {
+		adminRoles, err := d.googleClient.Directory.RetrieveAdminRoles(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to retrieve admin roles. Keeping the previous admin roles. Error: %v", err)
+			adminRoles = current.AdminRoles
+		}
+		data.AdminRoles = adminRoles
+	}
+
+	if d.syncDomains {
+		domains, err := d.googleClient.Directory.RetrieveDomains(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to retrieve domains. Keeping the previous domains. Error: %v", err)
+			domains = current.Domains
+		}
+		data.Domains = domains
 	}
-	d.status.KnownUsers = userCounter
+
+	return data
+}
+
+// publish makes the data available to readers as a new snapshot.
+func (d *dirSync) publish(data Data) *Snapshot {
+	d.publishMutex.Lock()
+	defer d.publishMutex.Unlock()
+	return d.publishLocked(data)
 }
 
-func (d *dirSync) updateGroups(groups map[string]*directory.Group) {
-	d.groups = groups
+// publishLocked publishes the data. The publishMutex has to be held.
+func (d *dirSync) publishLocked(data Data) *Snapshot {
+	d.generation++
+	snapshot := NewSnapshot(d.generation, data, d.maxNestingDepth)
+	d.changes.add(d.Snapshot(), snapshot)
+	d.snapshot.Store(snapshot)
+	d.subscribers.notify()
+
+	d.updateStatus(func(status *Status) {
+		status.Generation = snapshot.Generation
+		status.Hash = snapshot.Hash
+		status.KnownGroups = len(snapshot.Groups)
+		status.KnownUsers = snapshot.KnownMembers()
+		status.SyncedUsers = len(snapshot.Users)
+		status.SyncedOrgUnits = len(snapshot.OrgUnits)
+		status.SyncedDomains = len(snapshot.Domains)
+		status.GroupCycles = snapshot.Closure.Cycles
+		if snapshot.AdminRoles != nil {
+			status.SyncedAdminRoles = len(snapshot.AdminRoles.Roles)
+		}
+	})
+	return snapshot
+}
 
-	d.emailToMember = directory.ToEmailMemberMapping(groups)
-	d.memberIdToGroupIds = directory.ToMemberIdGroupIdsMapping(groups)
-	d.updateStatusCounter(groups)
+func (d *dirSync) updateStatus(update func(status *Status)) {
+	d.statusMutex.Lock()
+	defer d.statusMutex.Unlock()
+	update(&d.status)
 }
 
+// Status returns a copy of the current status.
 func (d *dirSync) Status() *Status {
-	return d.status
+	d.statusMutex.RLock()
+	defer d.statusMutex.RUnlock()
+	status := d.status
+	return &status
+}
+
+func (d *dirSync) History() *History {
+	return d.history.get()
+}
+
+func (d *dirSync) Changes(since uint64) (*ChangeFeed, *ResyncRequired) {
+	return d.changes.since(since)
+}
+
+func (d *dirSync) Subscribe() (<-chan struct{}, func()) {
+	return d.subscribers.subscribe()
+}
+
+func (d *dirSync) Snapshot() *Snapshot {
+	snapshot, ok := d.snapshot.Load().(*Snapshot)
+	if !ok {
+		return emptySnapshot
+	}
+	return snapshot
 }
 
 func (d *dirSync) Directory() map[string]*directory.Group {
-	return d.groups
+	return d.Snapshot().Groups
 }
 
 func (d *dirSync) MemberIdToGroupIdsMapping() map[string][]string {
-	return d.memberIdToGroupIds
+	return d.Snapshot().MemberIdToGroupIds
 }
 
 func (d *dirSync) EmailToMemberMapping() map[string]directory.MemberType {
-	return d.emailToMember
+	return d.Snapshot().EmailToMember
 }
 
-func (d *dirSync) persistToDisk(location string) error {
-	if location == "" {
+// persistToDisk writes the snapshot to the directory.json and the files of the
+// other synced data. Snapshots that are not newer than the last persisted one
+// are skipped, so concurrent callers never replace the files with older content.
+func (d *dirSync) persistToDisk(location string, snapshot *Snapshot) error {
+	if location == "" || snapshot.Groups == nil {
+		return nil
+	}
+
+	d.persistMutex.Lock()
+	defer d.persistMutex.Unlock()
+	if snapshot.Generation <= d.persistedGeneration {
 		return nil
 	}
 
-	data, err := json.Marshal(d.groups)
+	for _, file := range snapshot.Data.persistedFiles() {
+		if !file.present {
+			continue
+		}
+		data, err := json.Marshal(file.content)
+		if err != nil {
+			return err
+		}
+
+		err = writeFileAtomic(filepath.Join(location, file.name), data)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The generation is written last, so it never belongs to newer content
+	data, err := json.Marshal(persistedGeneration{Generation: snapshot.Generation})
 	if err != nil {
 		return err
 	}
-
-	err = ioutil.WriteFile(location+"/directory.json", data, 0644)
+	err = writeFileAtomic(filepath.Join(location, generationFile), data)
 	if err != nil {
 		return err
 	}
+	d.persistedGeneration = snapshot.Generation
+	return nil
+}
+
+const generationFile = "generation.json"
+
+// persistedGeneration is the generation of the persisted snapshot. The
+// generations continue from it after a restart, so clients holding a
+// generation of the previous process are never served a different history.
+type persistedGeneration struct {
+	Generation uint64 `json:"generation"`
+}
+
+type persistedFile struct {
+	name    string
+	content interface{}
+	present bool
+}
+
+// persistedFiles lists the files in the storage location together with the
+// part of the data they hold. Only the directory.json is mandatory.
+func (d *Data) persistedFiles() []persistedFile {
+	return []persistedFile{
+		{name: "directory.json", content: &d.Groups, present: true},
+		{name: "users.json", content: &d.Users, present: d.Users != nil},
+		{name: "orgunits.json", content: &d.OrgUnits, present: d.OrgUnits != nil},
+		{name: "adminroles.json", content: &d.AdminRoles, present: d.AdminRoles != nil},
+		{name: "domains.json", content: &d.Domains, present: d.Domains != nil},
+	}
+}
+
+// readOptionalFiles reads all data files except the directory.json from the
+// given directory. Missing files are skipped.
+func (d *Data) readOptionalFiles(location string) error {
+	for _, file := range d.persistedFiles()[1:] {
+		data, err := ioutil.ReadFile(filepath.Join(location, file.name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(data, file.content)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// writeFileAtomic writes the data to a temporary file first and renames it
+// afterwards. An interrupted write therefore never leaves a partial file.
+func writeFileAtomic(file string, data []byte) error {
+	tmpFile := file + ".tmp"
+	err := ioutil.WriteFile(tmpFile, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
 func (d *dirSync) restoreFromDisk(location string) error {
 	if location == "" {
 		return nil
@@ -202,12 +726,46 @@ func (d *dirSync) restoreFromDisk(location string) error {
 		return err
 	}
 
-	var groups map[string]*directory.Group
-	err = json.Unmarshal(data, &groups)
+	var restored Data
+	err = json.Unmarshal(data, &restored.Groups)
+	if err != nil {
+		return err
+	}
+	err = restored.readOptionalFiles(location)
 	if err != nil {
 		return err
 	}
 
-	d.updateGroups(groups)
+	generation, err := restoreGeneration(location)
+	if err != nil {
+		return err
+	}
+
+	d.publishMutex.Lock()
+	if generation > 0 {
+		d.generation = generation - 1
+	}
+	snapshot := d.publishLocked(restored)
+	d.publishMutex.Unlock()
+	d.persistedGeneration = snapshot.Generation
 	return nil
 }
+
+// restoreGeneration reads the generation of the persisted snapshot. Storage
+// locations written without it return 0.
+func restoreGeneration(location string) (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(location, generationFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var persisted persistedGeneration
+	err = json.Unmarshal(data, &persisted)
+	if err != nil {
+		return 0, err
+	}
+	return persisted.Generation, nil
+}
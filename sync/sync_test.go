@@ -2,7 +2,10 @@ package sync
 
 import (
 	"encoding/json"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 )
@@ -23,6 +26,140 @@ func TestJsonEncodeStatus(t *testing.T) {
 	b, err := json.Marshal(status)
 	a.Nil(err)
 
-	a.EqualValues(`{"last_sync":"2018-01-10T20:21:05Z","last_sync_duration":"15s","next_sync":"2018-01-10T20:51:05Z","known_groups":0,"known_users":0,"sync_in_progress":false}`, string(b))
+	a.EqualValues(`{"last_sync":"2018-01-10T20:21:05Z","last_sync_duration":"15s","next_sync":"2018-01-10T20:51:05Z","known_groups":0,"known_users":0,"sync_in_progress":false,"sync_workers":0,"last_full_sync":"0001-01-01T00:00:00Z","reused_groups":0,"refetched_groups":0,"last_change_sync":"0001-01-01T00:00:00Z","change_checkpoint":"0001-01-01T00:00:00Z","changed_groups":0,"api_calls":0,"retries":0,"throttled_time":"0s","backoff_time":"0s","generation":0,"hash":"","quarantined":false,"synced_users":0,"synced_org_units":0,"synced_admin_roles":0,"synced_domains":0}`, string(b))
 
 }
+
+func TestPublishSnapshot(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10)}
+	a.EqualValues(0, d.Snapshot().Generation)
+	a.Nil(d.Directory())
+
+	groups := map[string]*directory.Group{
+		"g1": {Id: "g1", Email: "group@your.org", Members: map[string]*directory.Member{
+			"m1": {Id: "m1", Email: "user@your.org", Type: "USER"},
+		}},
+	}
+
+	first := d.publish(Data{Groups: groups})
+	second := d.publish(Data{Groups: groups})
+	a.EqualValues(1, first.Generation)
+	a.EqualValues(2, second.Generation)
+	a.Equal(first.Hash, second.Hash)
+	a.NotEmpty(first.Hash)
+
+	snapshot := d.Snapshot()
+	a.Equal(second, snapshot)
+	a.Equal([]string{"g1"}, snapshot.MemberIdToGroupIds["m1"])
+	a.Equal("g1", snapshot.EmailToMember["group@your.org"].Id)
+	a.EqualValues(2, d.Status().Generation)
+	a.Equal(1, d.Status().KnownUsers)
+}
+
+func TestRestoreContinuesGeneration(t *testing.T) {
+	a := assert.New(t)
+
+	location, err := ioutil.TempDir("", "directory")
+	a.NoError(err)
+	defer os.RemoveAll(location)
+
+	groups := map[string]*directory.Group{"g1": {Id: "g1", Email: "group@your.org"}}
+	d := &dirSync{changes: newChangeLog(10)}
+	d.publish(Data{Groups: groups})
+	a.NoError(d.persistToDisk(location, d.publish(Data{Groups: groups})))
+
+	restored := &dirSync{changes: newChangeLog(10)}
+	a.NoError(restored.restoreFromDisk(location))
+	a.EqualValues(2, restored.Snapshot().Generation)
+	feed, resync := restored.Changes(2)
+	a.Nil(resync)
+	a.Empty(feed.Changes)
+
+	// History before the restart and generations the previous process did not
+	// persist are unknown
+	_, resync = restored.Changes(1)
+	a.NotNil(resync)
+	_, resync = restored.Changes(3)
+	a.NotNil(resync)
+
+	a.EqualValues(3, restored.publish(Data{Groups: groups}).Generation)
+}
+
+func TestConcurrentPublishAndRead(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			d.publish(Data{Groups: map[string]*directory.Group{"g1": {Id: "g1", Email: "group@your.org"}}})
+		}
+	}()
+
+	var last uint64
+	for i := 0; i < 100; i++ {
+		snapshot := d.Snapshot()
+		a.True(snapshot.Generation >= last)
+		last = snapshot.Generation
+		d.Status()
+	}
+	<-done
+	a.EqualValues(100, d.Snapshot().Generation)
+}
+
+func TestCheckThresholds(t *testing.T) {
+	a := assert.New(t)
+
+	member := &directory.Member{Id: "m1", Email: "user@your.org", Type: "USER"}
+	previous := NewSnapshot(1, Data{Groups: map[string]*directory.Group{
+		"g1": {Id: "g1", Members: map[string]*directory.Member{"m1": member}},
+		"g2": {Id: "g2", Members: map[string]*directory.Member{"m1": member}},
+		"g3": {Id: "g3"},
+		"g4": {Id: "g4"},
+	}}, 0)
+	next := NewSnapshot(2, Data{Groups: map[string]*directory.Group{
+		"g1": {Id: "g1", Members: map[string]*directory.Member{"m1": member}},
+		"g2": {Id: "g2"},
+		"g3": {Id: "g3"},
+	}}, 0)
+
+	a.Empty(checkThresholds(previous, next, Thresholds{}))
+	a.Empty(checkThresholds(previous, next, Thresholds{MaxGroupDrop: 25, MaxMemberDrop: 25}))
+
+	violations := checkThresholds(previous, next, Thresholds{MaxGroupDrop: 20, MaxMembershipDrop: 60})
+	a.Len(violations, 1)
+	a.Equal("groups", violations[0].Metric)
+	a.Equal(4, violations[0].Previous)
+	a.Equal(3, violations[0].Current)
+	a.EqualValues(25, violations[0].Drop)
+
+	violations = checkThresholds(previous, next, Thresholds{MaxMembershipDrop: 40})
+	a.Len(violations, 1)
+	a.Equal("memberships", violations[0].Metric)
+}
+
+func TestHistoryKeepsLastRuns(t *testing.T) {
+	a := assert.New(t)
+
+	h := newHistory(2)
+	h.add(&SyncRun{Outcome: OutcomeSuccess, Generation: 1})
+	h.add(&SyncRun{Outcome: OutcomeFailed, Error: "first"})
+	h.add(&SyncRun{Outcome: OutcomeFailed, Error: "second"})
+
+	history := h.get()
+	a.Len(history.Runs, 2)
+	a.Equal("second", history.Runs[0].Error)
+	a.Equal("first", history.Runs[1].Error)
+	a.False(history.Health.Healthy)
+	a.Equal(2, history.Health.ConsecutiveFailures)
+	a.Equal("second", history.Health.LastError)
+
+	h.add(&SyncRun{Outcome: OutcomeSuccess, Generation: 2})
+	history = h.get()
+	a.True(history.Health.Healthy)
+	a.Equal(0, history.Health.ConsecutiveFailures)
+	a.EqualValues(2, history.Runs[0].Generation)
+}
@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+// ChangeFilter restricts changes to the given groups and members. Both match
+// either the id or the canonical email address. Empty lists match all changes.
+type ChangeFilter struct {
+	groups  map[string]bool
+	members map[string]bool
+}
+
+func NewChangeFilter(groups []string, members []string, emails *directory.EmailIndex) *ChangeFilter {
+	return &ChangeFilter{
+		groups:  filterKeys(groups, emails),
+		members: filterKeys(members, emails),
+	}
+}
+
+func filterKeys(values []string, emails *directory.EmailIndex) map[string]bool {
+	keys := map[string]bool{}
+	for _, value := range values {
+		keys[value] = true
+		keys[emails.Canonical(value)] = true
+	}
+	return keys
+}
+
+func (f *ChangeFilter) Matches(change *Change, emails *directory.EmailIndex) bool {
+	if len(f.groups) > 0 && !f.groups[change.GroupId] && !f.groups[emails.Canonical(change.GroupEmail)] {
+		return false
+	}
+	if len(f.members) > 0 {
+		if change.MemberId == "" && change.MemberEmail == "" {
+			return false
+		}
+		if !f.members[change.MemberId] && !f.members[emails.Canonical(change.MemberEmail)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Watcher follows the published snapshots for a single client. The client
+// waits for Notifications and reads the events with Next.
+type Watcher struct {
+	dirSync       DirSync
+	filter        *ChangeFilter
+	notifications <-chan struct{}
+	unsubscribe   func()
+	// last is the generation of the last snapshot the client has seen
+	last   uint64
+	resume bool
+}
+
+// NewWatcher subscribes to the published snapshots. A watcher resuming after
+// the generation since first receives the changes it missed, otherwise it
+// starts with the current snapshot. Close has to be called once done.
+func NewWatcher(dirSync DirSync, since uint64, resume bool, filter *ChangeFilter) *Watcher {
+	notifications, unsubscribe := dirSync.Subscribe()
+	return &Watcher{
+		dirSync:       dirSync,
+		filter:        filter,
+		notifications: notifications,
+		unsubscribe:   unsubscribe,
+		last:          since,
+		resume:        resume,
+	}
+}
+
+// Notifications receives a notification whenever Next has new events.
+func (w *Watcher) Notifications() <-chan struct{} {
+	return w.notifications
+}
+
+func (w *Watcher) Close() {
+	w.unsubscribe()
+}
+
+// Next returns the matching changes after the last seen snapshot, oldest
+// first, and the current snapshot if the client has not seen it yet. If the
+// change log does not cover the changes, only resync is set and the watcher
+// cannot continue.
+func (w *Watcher) Next() ([]*Change, *Snapshot, *ResyncRequired) {
+	snapshot := w.dirSync.Snapshot()
+	if w.resume && w.last == snapshot.Generation {
+		return nil, nil, nil
+	}
+
+	changes := make([]*Change, 0)
+	if w.resume {
+		feed, resync := w.dirSync.Changes(w.last)
+		if resync != nil {
+			return nil, nil, resync
+		}
+		for _, change := range feed.Changes {
+			// The change log is updated before the snapshot is published,
+			// later changes are returned with the next snapshot
+			if change.Generation > snapshot.Generation {
+				break
+			}
+			if w.filter.Matches(change, snapshot.Emails) {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	// Changes are only available for generations published after the first
+	// one the client has seen
+	w.resume = snapshot.Generation > 0
+	w.last = snapshot.Generation
+	return changes, snapshot, nil
+}
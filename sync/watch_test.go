@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWatcher(t *testing.T) {
+	a := assert.New(t)
+
+	d := &dirSync{changes: newChangeLog(10)}
+	group := func(id string, members ...*directory.Member) *directory.Group {
+		group := &directory.Group{Id: id, Email: id + "@your.org", Members: map[string]*directory.Member{}}
+		for _, member := range members {
+			group.Members[member.Id] = member
+		}
+		return group
+	}
+	u1 := &directory.Member{Id: "u1", Email: "u1@your.org", Type: "USER"}
+	u2 := &directory.Member{Id: "u2", Email: "u2@your.org", Type: "USER"}
+
+	d.publish(Data{Groups: map[string]*directory.Group{"g1": group("g1"), "g2": group("g2")}})
+
+	watcher := NewWatcher(d, 0, false, NewChangeFilter([]string{"G1@your.org"}, nil, d.Snapshot().Emails))
+	defer watcher.Close()
+	changes, snapshot, resync := watcher.Next()
+	a.Empty(changes)
+	a.EqualValues(1, snapshot.Generation)
+	a.Nil(resync)
+
+	d.publish(Data{Groups: map[string]*directory.Group{"g1": group("g1", u1, u2), "g2": group("g2", u1)}})
+	<-watcher.Notifications()
+	changes, snapshot, _ = watcher.Next()
+	a.Len(changes, 2)
+	for _, change := range changes {
+		a.Equal("g1", change.GroupId)
+		a.Equal(directory.MemberAdded, change.Type)
+	}
+	a.EqualValues(2, snapshot.Generation)
+
+	// Nothing new since the last call
+	changes, snapshot, resync = watcher.Next()
+	a.Empty(changes)
+	a.Nil(snapshot)
+	a.Nil(resync)
+
+	// Member filters match the member id or email
+	resumed := NewWatcher(d, 1, true, NewChangeFilter(nil, []string{"u2"}, d.Snapshot().Emails))
+	defer resumed.Close()
+	changes, snapshot, _ = resumed.Next()
+	a.Len(changes, 1)
+	a.Equal("u2@your.org", changes[0].MemberEmail)
+	a.EqualValues(2, snapshot.Generation)
+
+	// Changes before the change log are not available anymore
+	_, snapshot, resync = NewWatcher(d, 0, true, NewChangeFilter(nil, nil, d.Snapshot().Emails)).Next()
+	a.Nil(snapshot)
+	a.NotNil(resync)
+}
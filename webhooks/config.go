@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+)
+
+// Config is a webhook subscription. Groups restricts the delivered changes to
+// the given groups (id or email) and Events to the given change types. Empty
+// lists deliver all changes.
+type Config struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Groups []string `json:"groups"`
+	Events []string `json:"events"`
+}
+
+// LoadConfig reads a JSON list of webhook subscriptions from the file.
+func LoadConfig(file string) ([]*Config, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read webhooks file %v: %v", file, err)
+	}
+
+	var configs []*Config
+	err = json.Unmarshal(content, &configs)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse webhooks file %v: %v", file, err)
+	}
+
+	err = validate(configs)
+	if err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func validate(configs []*Config) error {
+	changeTypes := map[string]bool{}
+	for _, changeType := range directory.ChangeTypes {
+		changeTypes[changeType] = true
+	}
+
+	names := map[string]bool{}
+	for _, config := range configs {
+		target, err := url.Parse(config.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("webhook %v has no valid http(s) url", config.URL)
+		}
+		if config.Name == "" {
+			config.Name = config.URL
+		}
+		if names[config.Name] {
+			return fmt.Errorf("webhook name %v is not unique", config.Name)
+		}
+		names[config.Name] = true
+
+		if config.Secret == "" {
+			return fmt.Errorf("webhook %v has no secret", config.Name)
+		}
+		for _, event := range config.Events {
+			if !changeTypes[event] {
+				return fmt.Errorf("webhook %v has unknown event type %v", config.Name, event)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"errors"
+	gosync "sync"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/sirupsen/logrus"
+)
+
+var errStopped = errors.New("webhook dispatcher stopped")
+
+// webhook holds the delivery queue and status of a single subscription.
+type webhook struct {
+	config *Config
+	queue  chan *Payload
+
+	mutex        gosync.Mutex
+	stopped      bool
+	pending      int
+	deliveries   int
+	retries      int
+	failures     int
+	misses       int
+	lastDelivery *time.Time
+	lastError    string
+	deadLetters  []*DeadLetter
+	next         int
+}
+
+func newWebhook(config *Config) *webhook {
+	return &webhook{
+		config:      config,
+		queue:       make(chan *Payload, queueSize),
+		deadLetters: make([]*DeadLetter, 0, deadLetterSize),
+	}
+}
+
+func (w *webhook) filter(changes []*sync.Change, emails *directory.EmailIndex) []*sync.Change {
+	return filterChanges(w.config, changes, emails)
+}
+
+// enqueue queues the payload without waiting. A payload that does not fit
+// into the queue or arrives after the dispatcher stopped is kept as dead
+// letter right away.
+func (w *webhook) enqueue(payload *Payload) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped {
+		logrus.Warnf("Dropped delivery %v to webhook %v: %v", payload.Id, w.config.Name, errStopped)
+		w.addDeadLetter(payload, 0, errStopped.Error())
+		return
+	}
+	select {
+	case w.queue <- payload:
+		w.pending++
+	default:
+		w.addDeadLetter(payload, 0, "delivery queue is full")
+	}
+}
+
+// stop keeps all queued payloads as dead letters, as they are not delivered
+// anymore once the dispatcher stopped.
+func (w *webhook) stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.stopped = true
+	for {
+		select {
+		case payload := <-w.queue:
+			logrus.Warnf("Dropped delivery %v to webhook %v: %v", payload.Id, w.config.Name, errStopped)
+			w.addDeadLetter(payload, 0, errStopped.Error())
+			w.pending--
+		default:
+			return
+		}
+	}
+}
+
+func (w *webhook) done() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.pending--
+}
+
+func (w *webhook) delivered() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	w.deliveries++
+	w.lastDelivery = &now
+}
+
+func (w *webhook) retried(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.retries++
+	w.lastError = err.Error()
+}
+
+func (w *webhook) missed() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.misses++
+}
+
+func (w *webhook) deadLetter(payload *Payload, attempts int, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.addDeadLetter(payload, attempts, err.Error())
+}
+
+// addDeadLetter keeps the last dead letters in a ring buffer. The mutex has to
+// be held.
+func (w *webhook) addDeadLetter(payload *Payload, attempts int, err string) {
+	deadLetter := &DeadLetter{
+		Time:     time.Now(),
+		Attempts: attempts,
+		Error:    err,
+		Payload:  payload,
+	}
+	if len(w.deadLetters) < cap(w.deadLetters) {
+		w.deadLetters = append(w.deadLetters, deadLetter)
+	} else {
+		w.deadLetters[w.next] = deadLetter
+	}
+	w.next = (w.next + 1) % cap(w.deadLetters)
+
+	w.failures++
+	w.lastError = err
+}
+
+func (w *webhook) status() *Status {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// Oldest dead letter first
+	deadLetters := make([]*DeadLetter, 0, len(w.deadLetters))
+	if len(w.deadLetters) == cap(w.deadLetters) {
+		deadLetters = append(deadLetters, w.deadLetters[w.next:]...)
+		deadLetters = append(deadLetters, w.deadLetters[:w.next]...)
+	} else {
+		deadLetters = append(deadLetters, w.deadLetters...)
+	}
+
+	return &Status{
+		Name:         w.config.Name,
+		URL:          w.config.URL,
+		Groups:       w.config.Groups,
+		Events:       w.config.Events,
+		Pending:      w.pending,
+		Delivered:    w.deliveries,
+		Retries:      w.retries,
+		Failed:       w.failures,
+		Missed:       w.misses,
+		LastDelivery: w.lastDelivery,
+		LastError:    w.lastError,
+		DeadLetters:  deadLetters,
+	}
+}
@@ -0,0 +1,290 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader holds "sha256=" followed by the hex encoded HMAC-SHA256
+	// of the request body, keyed with the secret of the webhook.
+	SignatureHeader = "X-Directory-Signature"
+	// DeliveryHeader holds the id of the payload, which stays the same for
+	// retries.
+	DeliveryHeader = "X-Directory-Delivery"
+
+	queueSize      = 100
+	deadLetterSize = 100
+	maxBackoff     = 5 * time.Minute
+	requestTimeout = 30 * time.Second
+)
+
+// Payload is the body posted to a webhook. It holds the matching changes
+// after the generation Since up to Generation, oldest first.
+type Payload struct {
+	Id         string         `json:"id"`
+	Webhook    string         `json:"webhook"`
+	Since      uint64         `json:"since"`
+	Generation uint64         `json:"generation"`
+	Hash       string         `json:"hash"`
+	Changes    []*sync.Change `json:"changes"`
+}
+
+// DeadLetter is a payload that could not be delivered.
+type DeadLetter struct {
+	Time     time.Time `json:"time"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Payload  *Payload  `json:"payload"`
+}
+
+// Status is the delivery status of a webhook. Missed counts the snapshots
+// whose changes were no longer in the change log when they were dispatched.
+type Status struct {
+	Name         string        `json:"name"`
+	URL          string        `json:"url"`
+	Groups       []string      `json:"groups"`
+	Events       []string      `json:"events"`
+	Pending      int           `json:"pending"`
+	Delivered    int           `json:"delivered"`
+	Retries      int           `json:"retries"`
+	Failed       int           `json:"failed"`
+	Missed       int           `json:"missed"`
+	LastDelivery *time.Time    `json:"last_delivery,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	DeadLetters  []*DeadLetter `json:"dead_letters"`
+}
+
+// Dispatcher delivers the changes of every published snapshot to the webhooks
+// whose filters match. Every webhook has its own queue and worker, so a slow
+// or failing webhook neither delays the others nor the sync loop.
+type Dispatcher struct {
+	dirSync     sync.DirSync
+	webhooks    []*webhook
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// New creates a dispatcher for the webhooks. Failed deliveries are attempted
+// up to maxAttempts times, waiting backoff before the first retry and twice as
+// long before every further one.
+func New(dirSync sync.DirSync, configs []*Config, maxAttempts int, backoff time.Duration) *Dispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	webhooks := make([]*webhook, 0, len(configs))
+	for _, config := range configs {
+		webhooks = append(webhooks, newWebhook(config))
+	}
+	return &Dispatcher{
+		dirSync:     dirSync,
+		webhooks:    webhooks,
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Run starts dispatching in the background until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if len(d.webhooks) == 0 {
+		return
+	}
+
+	notifications, unsubscribe := d.dirSync.Subscribe()
+	last := d.dirSync.Snapshot().Generation
+
+	for _, webhook := range d.webhooks {
+		go d.work(ctx, webhook)
+	}
+
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-notifications:
+				last = d.dispatch(last)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Status returns the delivery status of all webhooks.
+func (d *Dispatcher) Status() []*Status {
+	statuses := make([]*Status, 0, len(d.webhooks))
+	for _, webhook := range d.webhooks {
+		statuses = append(statuses, webhook.status())
+	}
+	return statuses
+}
+
+// dispatch queues the changes after the generation last up to the current
+// snapshot and returns the generation of the snapshot.
+func (d *Dispatcher) dispatch(last uint64) uint64 {
+	snapshot := d.dirSync.Snapshot()
+	// The first published snapshot has no previous one to compare against
+	if last == 0 || last == snapshot.Generation {
+		return snapshot.Generation
+	}
+
+	feed, resync := d.dirSync.Changes(last)
+	if resync != nil {
+		logrus.Warnf("Changes after generation %v are no longer available, webhooks miss them", last)
+		for _, webhook := range d.webhooks {
+			webhook.missed()
+		}
+		return snapshot.Generation
+	}
+
+	changes := make([]*sync.Change, 0, len(feed.Changes))
+	for _, change := range feed.Changes {
+		// The change log is updated before the snapshot is published, later
+		// changes are dispatched with the next snapshot
+		if change.Generation > snapshot.Generation {
+			break
+		}
+		changes = append(changes, change)
+	}
+
+	for _, webhook := range d.webhooks {
+		matching := webhook.filter(changes, snapshot.Emails)
+		if len(matching) == 0 {
+			continue
+		}
+		webhook.enqueue(&Payload{
+			Id:         fmt.Sprintf("%v-%v", webhook.config.Name, snapshot.Generation),
+			Webhook:    webhook.config.Name,
+			Since:      last,
+			Generation: snapshot.Generation,
+			Hash:       snapshot.Hash,
+			Changes:    matching,
+		})
+	}
+	return snapshot.Generation
+}
+
+func (d *Dispatcher) work(ctx context.Context, webhook *webhook) {
+	for {
+		select {
+		case payload := <-webhook.queue:
+			d.deliver(ctx, webhook, payload)
+		case <-ctx.Done():
+			webhook.stop()
+			return
+		}
+	}
+}
+
+// deliver posts the payload until the webhook accepts it or the attempts are
+// exhausted, in which case the payload is kept as dead letter.
+func (d *Dispatcher) deliver(ctx context.Context, webhook *webhook, payload *Payload) {
+	defer webhook.done()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		webhook.deadLetter(payload, 0, err)
+		return
+	}
+
+	delay := d.backoff
+	for attempt := 1; ; attempt++ {
+		err = d.post(ctx, webhook, payload.Id, body)
+		if err == nil {
+			webhook.delivered()
+			return
+		}
+		if attempt >= d.maxAttempts || sleep(ctx, delay) != nil {
+			logrus.Warnf("Failed to deliver %v to webhook %v after %v attempts: %v", payload.Id, webhook.config.Name, attempt, err)
+			webhook.deadLetter(payload, attempt, err)
+			return
+		}
+		webhook.retried(err)
+		delay *= 2
+		if delay > maxBackoff {
+			delay = maxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook *webhook, id string, body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, webhook.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(DeliveryHeader, id)
+	request.Header.Set(SignatureHeader, Sign(webhook.config.Secret, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %v", response.Status)
+	}
+	return nil
+}
+
+// Sign returns the value of the signature header for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// filterChanges returns the changes matching the group and event filters of
+// the webhook. Groups match by id or canonical email address.
+func filterChanges(config *Config, changes []*sync.Change, emails *directory.EmailIndex) []*sync.Change {
+	groups := map[string]bool{}
+	for _, group := range config.Groups {
+		groups[group] = true
+		groups[emails.Canonical(group)] = true
+	}
+	events := map[string]bool{}
+	for _, event := range config.Events {
+		events[event] = true
+	}
+
+	matching := make([]*sync.Change, 0)
+	for _, change := range changes {
+		if len(groups) > 0 && !groups[change.GroupId] && !groups[emails.Canonical(change.GroupEmail)] {
+			continue
+		}
+		if len(events) > 0 && !events[change.Type] {
+			continue
+		}
+		matching = append(matching, change)
+	}
+	return matching
+}
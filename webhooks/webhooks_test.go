@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabzo/gcloud-directory-service/sync"
+	"github.com/fabzo/gcloud-directory-service/sync/google/directory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterChanges(t *testing.T) {
+	a := assert.New(t)
+
+	emails := sync.NewSnapshot(1, sync.Data{}, 0).Emails
+	change := func(changeType string, groupId string, groupEmail string) *sync.Change {
+		return &sync.Change{Generation: 1, GroupChange: &directory.GroupChange{Type: changeType, GroupId: groupId, GroupEmail: groupEmail}}
+	}
+	changes := []*sync.Change{
+		change(directory.MemberAdded, "g1", "admins@your.org"),
+		change(directory.MemberRemoved, "g1", "admins@your.org"),
+		change(directory.MemberAdded, "g2", "team@your.org"),
+	}
+
+	a.Len(filterChanges(&Config{}, changes, emails), 3)
+	a.Len(filterChanges(&Config{Groups: []string{"Admins@Your.org"}}, changes, emails), 2)
+	a.Len(filterChanges(&Config{Groups: []string{"g2"}}, changes, emails), 1)
+
+	matching := filterChanges(&Config{Groups: []string{"g1"}, Events: []string{directory.MemberRemoved}}, changes, emails)
+	a.Len(matching, 1)
+	a.Equal(directory.MemberRemoved, matching[0].Type)
+}
+
+func TestValidateConfig(t *testing.T) {
+	a := assert.New(t)
+
+	config := &Config{URL: "https://hooks.your.org/directory", Secret: "secret"}
+	a.Nil(validate([]*Config{config}))
+	a.Equal(config.URL, config.Name)
+
+	a.NotNil(validate([]*Config{{URL: "ftp://hooks.your.org", Secret: "secret"}}))
+	a.NotNil(validate([]*Config{{URL: "https://hooks.your.org"}}))
+	a.NotNil(validate([]*Config{{URL: "https://hooks.your.org", Secret: "secret", Events: []string{"unknown"}}}))
+	a.NotNil(validate([]*Config{
+		{Name: "hook", URL: "https://hooks.your.org/1", Secret: "secret"},
+		{Name: "hook", URL: "https://hooks.your.org/2", Secret: "secret"},
+	}))
+}
+
+func TestDeliver(t *testing.T) {
+	a := assert.New(t)
+
+	var attempts int
+	var signature, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		content, _ := ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		body = string(content)
+		if attempts < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	dispatcher := New(nil, []*Config{{Name: "hook", URL: server.URL, Secret: "secret"}}, 3, time.Millisecond)
+	webhook := dispatcher.webhooks[0]
+	payload := &Payload{Id: "hook-2", Webhook: "hook", Since: 1, Generation: 2}
+
+	// The first attempt fails and is retried
+	webhook.enqueue(payload)
+	dispatcher.deliver(context.Background(), webhook, <-webhook.queue)
+	a.Equal(2, attempts)
+	a.Equal(Sign("secret", []byte(body)), signature)
+	status := dispatcher.Status()[0]
+	a.Equal(1, status.Delivered)
+	a.Equal(1, status.Retries)
+	a.Equal(0, status.Pending)
+	a.Empty(status.DeadLetters)
+
+	// Payloads failing every attempt become dead letters
+	attempts = -10
+	webhook.enqueue(payload)
+	dispatcher.deliver(context.Background(), webhook, <-webhook.queue)
+	a.Equal(-7, attempts)
+	status = dispatcher.Status()[0]
+	a.Equal(1, status.Failed)
+	a.Len(status.DeadLetters, 1)
+	a.Equal(3, status.DeadLetters[0].Attempts)
+	a.Equal(payload, status.DeadLetters[0].Payload)
+	a.Contains(status.LastError, "503")
+}
+
+func TestStopKeepsQueuedPayloads(t *testing.T) {
+	a := assert.New(t)
+
+	dispatcher := New(nil, []*Config{{Name: "hook", URL: "http://localhost"}}, 3, time.Millisecond)
+	webhook := dispatcher.webhooks[0]
+	webhook.enqueue(&Payload{Id: "hook-2"})
+	webhook.enqueue(&Payload{Id: "hook-3"})
+
+	webhook.stop()
+	status := dispatcher.Status()[0]
+	a.Equal(0, status.Pending)
+	a.Equal(2, status.Failed)
+	a.Len(status.DeadLetters, 2)
+	a.Equal("hook-2", status.DeadLetters[0].Payload.Id)
+	a.Equal(errStopped.Error(), status.DeadLetters[0].Error)
+
+	// Payloads dispatched after the stop are not queued anymore
+	webhook.enqueue(&Payload{Id: "hook-4"})
+	a.Empty(webhook.queue)
+	a.Len(dispatcher.Status()[0].DeadLetters, 3)
+}